@@ -0,0 +1,100 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"reflect"
+)
+
+// FieldChange describes a field whose value differs from its default.
+type FieldChange struct {
+	// Path is the dotted path of the field, like `Server.Timeout`
+	Path string
+	// Old is the default value of the field
+	Old any
+	// New is the current value of the field
+	New any
+}
+
+// Changed loads the defaults of the type of `ptr` into a new value,
+// and returns a FieldChange for every exported field of `ptr` whose value differs.
+// Nested structs are compared field by field, other values like slices and maps as a whole.
+// `ptr` should be a struct pointer
+func Changed[T any](ptr *T, opts ...Option) ([]FieldChange, error) {
+	return defaultLoader.with(opts...).Changed(ptr)
+}
+
+// Changed loads the defaults of the type of `ptr` into a new value,
+// and returns a FieldChange for every exported field of `ptr` whose value differs.
+// Nested structs are compared field by field, other values like slices and maps as a whole.
+// `ptr` should be a struct pointer
+func (l *Loader) Changed(ptr any) ([]FieldChange, error) {
+	v, err := structOf(ptr)
+	if err != nil {
+		return nil, err
+	}
+	defaults := reflect.New(v.Type())
+	if err := l.Load(defaults.Interface()); err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	appendChanges(&changes, make(fieldPath, 0, 8), defaults.Elem(), v)
+	return changes, nil
+}
+
+// appendChanges compares the exported fields of the structs old and cur.
+func appendChanges(changes *[]FieldChange, path fieldPath, old, cur reflect.Value) {
+	t := cur.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		of, cf := old.Field(i), cur.Field(i)
+		if sf.Anonymous && !sf.IsExported() && isStruct(sf.Type) {
+			of, cf = embeddedField(of), embeddedField(cf)
+			if sf.Type.Kind() == reflect.Ptr && of.IsNil() != cf.IsNil() {
+				// a nil struct has no promoted fields to compare, it is named by its type
+				appendValueChanges(changes, path.push(sf.Name), of, cf)
+				continue
+			}
+			// promoted fields keep their own names
+			appendValueChanges(changes, path, of, cf)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		appendValueChanges(changes, path.push(sf.Name), of, cf)
+	}
+}
+
+func appendValueChanges(changes *[]FieldChange, path fieldPath, old, cur reflect.Value) {
+	switch {
+	case cur.Kind() == reflect.Struct && hasExportedFields(cur.Type()):
+		appendChanges(changes, path, old, cur)
+		return
+	case cur.Kind() == reflect.Ptr && !old.IsNil() && !cur.IsNil() && cur.Elem().Kind() == reflect.Struct &&
+		hasExportedFields(cur.Type().Elem()):
+		appendChanges(changes, path, old.Elem(), cur.Elem())
+		return
+	}
+	if reflect.DeepEqual(old.Interface(), cur.Interface()) {
+		return
+	}
+	*changes = append(*changes, FieldChange{
+		Path: path.String(),
+		Old:  old.Interface(),
+		New:  cur.Interface(),
+	})
+}
+
+// hasExportedFields reports whether the struct type t is compared field by field,
+// structs like time.Time which only hold unexported fields are compared as a whole.
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() || t.Field(i).Anonymous {
+			return true
+		}
+	}
+	return false
+}
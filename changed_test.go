@@ -0,0 +1,65 @@
+package dl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChanged(t *testing.T) {
+	c := &resetConfig{}
+	if err := Load(c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	changes, err := Changed(c)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("it should not report loaded defaults, got %v, %v", changes, err)
+	}
+
+	c.Name = "set"
+	c.Burst = 20
+	c.Server.Timeout = time.Minute
+	c.Server.Labels = map[string]string{"a": "b"}
+	c.Backup = &resetServer{}
+	changes, err = Changed(c)
+	if err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	expected := []FieldChange{
+		{Path: "Burst", Old: 10, New: 20},
+		{Path: "Name", Old: "app", New: "set"},
+		{Path: "Server.Timeout", Old: 5 * time.Second, New: time.Minute},
+		{Path: "Server.Labels", Old: map[string]string(nil), New: map[string]string{"a": "b"}},
+		{Path: "Backup", Old: (*resetServer)(nil), New: &resetServer{}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("it should report the changed fields\ngot  %+v\nwant %+v", changes, expected)
+	}
+
+	type schedule struct {
+		Start time.Time `default:"2024-01-01T00:00:00Z"`
+	}
+	s := &schedule{Start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}
+	changes, err = Changed(s)
+	if err != nil || len(changes) != 1 || changes[0].Path != "Start" {
+		t.Errorf("it should compare structs without exported fields as a whole, got %v, %v", changes, err)
+	}
+
+	e := &struct {
+		*resetLimits
+		Name string
+	}{resetLimits: &resetLimits{Burst: 20}}
+	changes, err = Changed(e)
+	if err != nil || len(changes) != 1 || changes[0].Path != "resetLimits" || changes[0].New != e.resetLimits {
+		t.Errorf("it should name nil embedded structs by their type, got %+v, %v", changes, err)
+	}
+
+	_, err = Changed(&struct {
+		Port int `default:"80a"`
+	}{}, WithStrict(true))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Errorf("it should return the error of loading the defaults, got %v", err)
+	}
+}
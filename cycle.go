@@ -0,0 +1,62 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var defaultLoaderType = reflect.TypeOf((*DefaultLoader)(nil)).Elem()
+
+// visit is a struct being loaded, the walker keeps one for each ancestor of the current field.
+type visit struct {
+	typ   reflect.Type
+	addr  uintptr
+	value reflect.Value
+	// depth is the length of the path when the struct was entered
+	depth int
+}
+
+// enter pushes v on the stack of structs being loaded.
+// It returns false when v is already being loaded, which happens for pointer cycles in the data.
+func (w *walker) enter(v reflect.Value) (bool, error) {
+	if w.l.maxDepth > 0 && len(w.stack) >= w.l.maxDepth {
+		return false, w.fail(v, "", fmt.Errorf("%w: %d", ErrMaxDepth, w.l.maxDepth))
+	}
+	var addr uintptr
+	if v.CanAddr() {
+		addr = v.Addr().Pointer()
+		for _, s := range w.stack {
+			if s.addr == addr && s.typ == v.Type() {
+				return false, nil
+			}
+		}
+	}
+	w.stack = append(w.stack, visit{typ: v.Type(), addr: addr, value: v, depth: len(w.path)})
+	return true, nil
+}
+
+func (w *walker) leave() {
+	w.stack = w.stack[:len(w.stack)-1]
+}
+
+// checkCycle reports a cycle when a default allocates a struct of type t while t is already being loaded,
+// because the new struct would allocate another one by the same default forever.
+// Types implementing DefaultLoader are skipped as their Default decides what is allocated.
+// It returns true when a cycle is found, the default must not be applied even if the error is collected.
+func (w *walker) checkCycle(field reflect.Value, t reflect.Type, defaultVal string) (bool, error) {
+	t = indirect(t)
+	if t.Kind() != reflect.Struct || reflect.PtrTo(t).Implements(defaultLoaderType) {
+		return false, nil
+	}
+	for _, s := range w.stack {
+		if s.typ == t {
+			err := fmt.Errorf("%w: %s allocates %s already loaded at %s",
+				ErrCycle, w.path, field.Type(), w.path[:s.depth])
+			return true, w.fail(field, defaultVal, err)
+		}
+	}
+	return false, nil
+}
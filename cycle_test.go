@@ -0,0 +1,133 @@
+package dl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type Node struct {
+	Value int   `default:"1"`
+	Next  *Node `default:"{}"`
+}
+
+type Tree struct {
+	Name  string `default:"tree"`
+	Left  *Tree
+	Right *Tree
+}
+
+type Rule struct {
+	Name string `default:"rule"`
+	Sub  *SubRule
+}
+
+type SubRule struct {
+	Parent *Rule `default:"{}"`
+}
+
+type RuleSet struct {
+	Name     string             `default:"set"`
+	Children []RuleSet          `default:"[{\"Name\": \"x\"}]"`
+	Named    map[string]RuleSet `default:"{\"a\": {}}"`
+	Pair     [2]*RuleSet        `default:"[{}]"`
+}
+
+type LoaderNode struct {
+	Next *LoaderNode `default:"{}"`
+}
+
+func (n *LoaderNode) Default() error {
+	return nil
+}
+
+func TestCycle(t *testing.T) {
+	t.Run("self reference", func(t *testing.T) {
+		err := Load(&Node{})
+		if !errors.Is(err, ErrCycle) {
+			t.Fatalf("it should return ErrCycle, got %v", err)
+		}
+		var e *FieldError
+		if !errors.As(err, &e) || e.Path != "Node.Next" {
+			t.Errorf("it should report the field path, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "already loaded at Node") {
+			t.Errorf("it should describe the cycle, got %v", err)
+		}
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+		n := &Node{}
+		err := Load(n, WithAllErrors(true))
+		if !errors.Is(err, ErrCycle) {
+			t.Fatalf("it should return ErrCycle, got %v", err)
+		}
+		if n.Next != nil {
+			t.Errorf("it should not allocate the pointer, got %+v", n.Next)
+		}
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		s := &RuleSet{}
+		err := Load(s, WithAllErrors(true))
+		var errs FieldErrors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("it should return a cycle for each field, got %v", err)
+		}
+		for i, path := range []string{"RuleSet.Children", "RuleSet.Named", "RuleSet.Pair"} {
+			if !errors.Is(errs[i], ErrCycle) || errs[i].Path != path {
+				t.Errorf("it should return ErrCycle for %s, got %v", path, errs[i])
+			}
+		}
+		if s.Name != "set" || s.Children != nil || s.Named != nil || s.Pair[0] != nil {
+			t.Errorf("it should not set the recursive defaults, got %+v", s)
+		}
+	})
+
+	t.Run("indirect reference", func(t *testing.T) {
+		err := Load(&Rule{Sub: &SubRule{}})
+		if !errors.Is(err, ErrCycle) {
+			t.Fatalf("it should return ErrCycle, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "Rule.Sub.Parent allocates *dl.Rule already loaded at Rule") {
+			t.Errorf("it should describe the cycle path, got %v", err)
+		}
+	})
+
+	t.Run("pointer cycle", func(t *testing.T) {
+		root := &Tree{}
+		root.Left = &Tree{Right: root}
+		root.Right = root
+		if err := Load(root); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if root.Name != "tree" || root.Left.Name != "tree" {
+			t.Errorf("it should load every node once, got %s and %s", root.Name, root.Left.Name)
+		}
+	})
+
+	t.Run("default loader", func(t *testing.T) {
+		node := &LoaderNode{}
+		if err := LoadStruct(node); err != nil {
+			t.Fatalf("it should not report types loaded by their Default: %v", err)
+		}
+		if node.Next == nil {
+			t.Errorf("it should allocate the pointer")
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		list := &Tree{Left: &Tree{Left: &Tree{Left: &Tree{}}}}
+		err := Load(list, WithMaxDepth(3))
+		if !errors.Is(err, ErrMaxDepth) {
+			t.Fatalf("it should return ErrMaxDepth, got %v", err)
+		}
+		var e *FieldError
+		if !errors.As(err, &e) || e.Path != "Tree.Left.Left.Left" {
+			t.Errorf("it should report the field path, got %v", err)
+		}
+		if err := Load(list, WithMaxDepth(4)); err != nil {
+			t.Errorf("it should not return an error: %v", err)
+		}
+	})
+}
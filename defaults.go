@@ -9,114 +9,357 @@ import (
 	"reflect"
 	"strconv"
 	"time"
+	"unsafe"
 )
 
-const (
-	fieldName = "default"
-)
+func (l *Loader) setDefaults(ptr interface{}, loadInterface func(ptr any) (bool, error)) error {
+	v, err := structOf(ptr)
+	if err != nil {
+		return err
+	}
+
+	w := newWalker(l, v.Type(), loadInterface)
+	if err := w.setStruct(v); err != nil {
+		return err
+	}
+	return w.err()
+}
 
-func setDefaults(ptr interface{}) error {
+// structOf returns the struct referenced by ptr.
+func structOf(ptr any) (reflect.Value, error) {
 	kind := reflect.TypeOf(ptr).Kind()
 	if kind != reflect.Ptr {
-		return InvalidTypeError(kind.String())
+		return reflect.Value{}, InvalidTypeError(kind.String())
 	}
 
 	v := reflect.ValueOf(ptr).Elem()
-	t := v.Type()
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, InvalidTypeError(v.Kind().String())
+	}
+	return v, nil
+}
+
+// walker carries the state of a single load call through the recursion.
+type walker struct {
+	l *Loader
+	// loadInterface dispatches nested values to their DefaultLoader or DefaultOptionLoader
+	loadInterface func(ptr any) (bool, error)
+	path          fieldPath
+	stack         []visit
+	// refs are the fields whose defaults reference fields which may not be loaded yet
+	refs []*pendingRef
+	// shallow only parses the tags, no hook is called and no interface is created by a factory
+	shallow bool
+	errs    FieldErrors
+}
 
-	if t.Kind() != reflect.Struct {
-		return InvalidTypeError(t.Kind().String())
+func newWalker(l *Loader, root reflect.Type, loadInterface func(ptr any) (bool, error)) *walker {
+	w := &walker{l: l, loadInterface: loadInterface, path: make(fieldPath, 0, 8)}
+	if root.Name() != "" {
+		w.path = w.path.push(root.Name())
 	}
+	return w
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		if defaultVal := t.Field(i).Tag.Get(fieldName); defaultVal != "-" {
-			if err := setField(v.Field(i), defaultVal); err != nil {
-				return err
-			}
+// fail wraps err with the current field path, it is collected instead of returned when all errors are wanted.
+func (w *walker) fail(field reflect.Value, defaultVal string, err error) error {
+	fe := &FieldError{
+		Path:  w.path.String(),
+		Type:  field.Type(),
+		Value: defaultVal,
+		Err:   err,
+	}
+	if w.l.allErrors {
+		w.errs = append(w.errs, fe)
+		return nil
+	}
+	return fe
+}
+
+func (w *walker) err() error {
+	if len(w.errs) == 0 {
+		return nil
+	}
+	return w.errs
+}
+
+func (w *walker) setStruct(v reflect.Value) error {
+	if ok, err := w.enter(v); !ok {
+		return err
+	}
+	defer w.leave()
+
+	ptr := v.Addr().Interface()
+	if w.shallow {
+		return w.setFields(v)
+	}
+	if hook, ok := ptr.(BeforeDefaultsHook); ok {
+		if err := hook.BeforeDefaults(); err != nil {
+			return w.fail(v, "", err)
+		}
+	}
+
+	if err := w.setFields(v); err != nil {
+		return err
+	}
+
+	if hook, ok := ptr.(AfterDefaultsHook); ok {
+		if err := hook.AfterDefaults(); err != nil {
+			return w.fail(v, "", err)
+		}
+	}
+	for _, hook := range w.l.hooks {
+		if err := hook(ptr); err != nil {
+			return w.fail(v, "", err)
+		}
+	}
+	return nil
+}
+
+// setFields sets the fields of the struct v from their defaults, then the references to its fields.
+func (w *walker) setFields(v reflect.Value) error {
+	fields := w.l.planOf(v.Type()).fields
+	for i := range fields {
+		f := &fields[i]
+		def := f.def
+		if f.env != "" || def.expand {
+			def = w.l.resolveDefault(f)
+		}
+		field := v.Field(f.index)
+		if f.hidden {
+			field = embeddedField(field)
+		}
+		w.path = w.path.push(f.name)
+		err := w.setDefault(field, def)
+		w.path = w.path.pop()
+		if err != nil {
+			return err
 		}
 	}
+	if len(w.refs) > 0 {
+		depth := len(w.stack) - 1
+		if err := w.resolveRefs(func(r *pendingRef) bool { return r.anchor == depth }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDefault sets the field from def, a default referencing other fields is set once they are loaded.
+func (w *walker) setDefault(field reflect.Value, def *fieldDefault) error {
+	if def.ref != nil {
+		return w.deferRef(field, def)
+	}
+	return w.setField(field, def)
+}
 
+// embeddedField returns a settable view of an unexported embedded struct or struct pointer,
+// so its promoted exported fields get their defaults like the fields of any other nested struct.
+func embeddedField(field reflect.Value) reflect.Value {
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem() //nolint:gosec
+}
+
+func setBoolField(field reflect.Value, defaultVal string) error {
+	val, err := strconv.ParseBool(defaultVal)
+	if err != nil {
+		return parseError(field.Kind(), defaultVal, err)
+	}
+	field.SetBool(val)
 	return nil
 }
 
-func setIntField(field reflect.Value, defaultVal string, size int) {
+func setIntField(field reflect.Value, defaultVal string, size int) error {
 	if size == 64 {
 		if val, err := time.ParseDuration(defaultVal); err == nil {
 			field.Set(reflect.ValueOf(val).Convert(field.Type()))
-			return
+			return nil
 		}
 	}
-	if val, err := strconv.ParseInt(defaultVal, 0, size); err == nil {
-		field.SetInt(val)
+	val, err := strconv.ParseInt(defaultVal, 0, size)
+	if err != nil {
+		return parseError(field.Kind(), defaultVal, err)
+	}
+	field.SetInt(val)
+	return nil
+}
+
+func setUintField(field reflect.Value, defaultVal string, size int) error {
+	val, err := strconv.ParseUint(defaultVal, 0, size)
+	if err != nil {
+		return parseError(field.Kind(), defaultVal, err)
+	}
+	field.SetUint(val)
+	return nil
+}
+
+func setFloatField(field reflect.Value, defaultVal string, size int) error {
+	val, err := strconv.ParseFloat(defaultVal, size)
+	if err != nil {
+		return parseError(field.Kind(), defaultVal, err)
 	}
+	field.SetFloat(val)
+	return nil
 }
 
-func setUintField(field reflect.Value, defaultVal string, size int) {
-	if val, err := strconv.ParseUint(defaultVal, 0, size); err == nil {
-		field.SetUint(val)
+// parseScalar parses defaultVal by the field kind, or by unit for integers when it is set.
+func parseScalar(field reflect.Value, defaultVal string, unit string) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if unit != "" {
+			return setUnitField(field, defaultVal, unit)
+		}
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		return setBoolField(field, defaultVal)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setIntField(field, defaultVal, field.Type().Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return setUintField(field, defaultVal, field.Type().Bits())
+	case reflect.Float32, reflect.Float64:
+		return setFloatField(field, defaultVal, field.Type().Bits())
+	default:
+		return nil
 	}
 }
 
-func setFloatField(field reflect.Value, defaultVal string, size int) {
-	if val, err := strconv.ParseFloat(defaultVal, size); err == nil {
-		field.SetFloat(val)
+// setScalar uses the value parsed by the plan when it was compiled for this type.
+// Parse errors are reported only in strict mode, lenient loading keeps the field untouched.
+func (w *walker) setScalar(field reflect.Value, def *fieldDefault) error {
+	unit := def.unit
+	if unit == "" {
+		unit = w.l.unitFor(field.Type())
 	}
+	var err error
+	if def.typ == field.Type() && unit == def.unit {
+		err = def.err
+		if err == nil {
+			field.Set(def.value)
+		}
+	} else {
+		err = parseScalar(field, def.raw, unit)
+	}
+	if err != nil && w.l.strict {
+		return w.fail(field, def.raw, err)
+	}
+	return nil
 }
 
-func setField(field reflect.Value, defaultVal string) error {
+func (w *walker) setField(field reflect.Value, def *fieldDefault) error {
 	if !field.CanSet() {
 		return nil
 	}
 
+	defaultVal := def.raw
 	if !shouldInitializeField(field, defaultVal) {
 		return nil
 	}
 
-	isInitial := isInitialValue(field)
+	isInitial := isInitialValue(field) || (w.l.mode == Overwrite && defaultVal != "")
+	if !isInitial && w.l.mode == Merge && defaultVal != "" {
+		if err := w.mergeField(field, def); err != nil {
+			return err
+		}
+	}
 	if isInitial {
-		if unmarshalByInterface(field, defaultVal) {
+		if ok, err := w.parseByParser(field, defaultVal); ok {
+			return err
+		}
+		if ok, err := w.setTimeField(field, def); ok {
+			return err
+		}
+		if def.typ == nil && unmarshalByInterface(field, defaultVal) {
 			return nil
 		}
 
 		switch field.Kind() {
-		case reflect.Bool:
-			if val, err := strconv.ParseBool(defaultVal); err == nil {
-				field.SetBool(val)
-			}
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			setIntField(field, defaultVal, field.Type().Bits())
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-			setUintField(field, defaultVal, field.Type().Bits())
-		case reflect.Float32, reflect.Float64:
-			setFloatField(field, defaultVal, field.Type().Bits())
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			if err := w.setScalar(field, def); err != nil {
+				return err
+			}
 		case reflect.String:
 			field.SetString(defaultVal)
 		case reflect.Slice:
+			if defaultVal != "" && defaultVal != "[]" {
+				if cycle, err := w.checkCycle(field, field.Type().Elem(), defaultVal); cycle {
+					return err
+				}
+			}
+			if defaultVal != "" && defaultVal != "[]" && w.l.hasParser(field.Type().Elem()) {
+				if err := w.setListByParser(field, defaultVal); err != nil {
+					return err
+				}
+				break
+			}
 			ref := reflect.New(field.Type())
 			ref.Elem().Set(reflect.MakeSlice(field.Type(), 0, 0))
 			if defaultVal != "" && defaultVal != "[]" {
 				if err := json.Unmarshal([]byte(defaultVal), ref.Interface()); err != nil {
-					return err
+					return w.fail(field, defaultVal, err)
 				}
 			}
 			field.Set(ref.Elem().Convert(field.Type()))
 		case reflect.Map:
+			if defaultVal != "" && defaultVal != "{}" {
+				if cycle, err := w.checkCycle(field, field.Type().Elem(), defaultVal); cycle {
+					return err
+				}
+			}
+			if defaultVal != "" && defaultVal != "{}" && w.l.hasParser(field.Type().Elem()) {
+				if err := w.setMapByParser(field, defaultVal); err != nil {
+					return err
+				}
+				break
+			}
 			ref := reflect.New(field.Type())
 			ref.Elem().Set(reflect.MakeMap(field.Type()))
 			if defaultVal != "" && defaultVal != "{}" {
 				if err := json.Unmarshal([]byte(defaultVal), ref.Interface()); err != nil {
-					return err
+					return w.fail(field, defaultVal, err)
 				}
 			}
 			field.Set(ref.Elem().Convert(field.Type()))
+		case reflect.Array:
+			if defaultVal == "" || defaultVal == "[]" {
+				break
+			}
+			if cycle, err := w.checkCycle(field, field.Type().Elem(), defaultVal); cycle {
+				return err
+			}
+			setArray := w.setArrayField
+			if w.l.hasParser(field.Type().Elem()) {
+				setArray = w.setListByParser
+			}
+			if err := setArray(field, defaultVal); err != nil {
+				return err
+			}
 		case reflect.Struct:
-			if defaultVal != "" && defaultVal != "{}" {
-				if err := json.Unmarshal([]byte(defaultVal), field.Addr().Interface()); err != nil {
-					return err
+			if w.l.mode == Overwrite {
+				// the struct is reset, its tag is applied over the defaults of its fields below
+				if defaultVal != "" {
+					field.Set(reflect.Zero(field.Type()))
 				}
+				break
+			}
+			if err := w.setStructField(field, defaultVal); err != nil {
+				return err
 			}
 		case reflect.Ptr:
+			if cycle, err := w.checkCycle(field, field.Type().Elem(), defaultVal); cycle {
+				return err
+			}
 			field.Set(reflect.New(field.Type().Elem()))
+		case reflect.Interface:
+			if defaultVal == "" || w.shallow {
+				break
+			}
+			if err := w.setInterfaceField(field, defaultVal); err != nil {
+				return err
+			}
 		default:
 			// nothing to do
 		}
@@ -124,19 +367,52 @@ func setField(field reflect.Value, defaultVal string) error {
 
 	switch field.Kind() {
 	case reflect.Ptr:
-		if isInitial || field.Elem().Kind() == reflect.Struct {
-			err := setField(field.Elem(), defaultVal)
+		if isInitial || field.Elem().Kind() == reflect.Struct || w.l.mode == Merge {
+			err := w.setField(field.Elem(), def)
 			if err != nil {
 				return err
 			}
 		}
 	case reflect.Struct:
-		if err := LoadStruct(field.Addr().Interface()); err != nil {
+		if ok, err := w.loadInterface(field.Addr().Interface()); ok {
+			if err != nil {
+				return w.fail(field, defaultVal, err)
+			}
+		} else if err := w.setStruct(field); err != nil {
 			return err
 		}
+		if isInitial && w.l.mode == Overwrite {
+			return w.setStructField(field, defaultVal)
+		}
 	case reflect.Slice:
+		elemDef := def
+		if w.l.mode != FillZero || w.l.hasParser(field.Type().Elem()) {
+			// the elements are already set from the default, only their own defaults are left
+			elemDef = noDefault
+		}
 		for j := 0; j < field.Len(); j++ {
-			if err := setField(field.Index(j), defaultVal); err != nil {
+			w.path = w.path.pushIndex(j)
+			err := w.setField(field.Index(j), elemDef)
+			w.path = w.path.pop()
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		if !isContainer(field.Type().Elem()) {
+			break
+		}
+		for j := 0; j < field.Len(); j++ {
+			w.path = w.path.pushIndex(j)
+			err := w.setField(field.Index(j), noDefault)
+			w.path = w.path.pop()
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Interface:
+		if !field.IsNil() {
+			if err := w.setInterfaceValue(field); err != nil {
 				return err
 			}
 		}
@@ -144,25 +420,11 @@ func setField(field reflect.Value, defaultVal string) error {
 		for _, e := range field.MapKeys() {
 			var v = field.MapIndex(e)
 
-			switch v.Kind() {
-			case reflect.Ptr:
-				switch v.Elem().Kind() {
-				case reflect.Struct, reflect.Slice, reflect.Map:
-					if err := setField(v.Elem(), ""); err != nil {
-						return err
-					}
-				default:
-					// nothing to do
-				}
-			case reflect.Struct, reflect.Slice, reflect.Map:
-				ref := reflect.New(v.Type())
-				ref.Elem().Set(v)
-				if err := setField(ref.Elem(), ""); err != nil {
-					return err
-				}
-				field.SetMapIndex(e, ref.Elem().Convert(v.Type()))
-			default:
-				// nothing to do
+			w.path = w.path.pushKey(e)
+			err := w.setMapValue(field, e, v)
+			w.path = w.path.pop()
+			if err != nil {
+				return err
 			}
 		}
 	default:
@@ -172,6 +434,100 @@ func setField(field reflect.Value, defaultVal string) error {
 	return nil
 }
 
+// setStructField sets a struct from the json object of its tag.
+func (w *walker) setStructField(field reflect.Value, defaultVal string) error {
+	if defaultVal == "" || defaultVal == "{}" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(defaultVal), field.Addr().Interface()); err != nil {
+		return w.fail(field, defaultVal, err)
+	}
+	return nil
+}
+
+// mergeField adds the default map keys missing in a non-empty map,
+// and appends the default slice elements which are not equal to any element of a non-empty slice.
+func (w *walker) mergeField(field reflect.Value, def *fieldDefault) error {
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Map {
+		return nil
+	}
+	defaults := reflect.New(field.Type()).Elem()
+	if err := w.setField(defaults, def); err != nil {
+		return err
+	}
+
+	if field.Kind() == reflect.Map {
+		iter := defaults.MapRange()
+		for iter.Next() {
+			if !field.MapIndex(iter.Key()).IsValid() {
+				field.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return nil
+	}
+
+	merged := field
+	for i := 0; i < defaults.Len(); i++ {
+		if !containsElem(field, defaults.Index(i)) {
+			merged = reflect.Append(merged, defaults.Index(i))
+		}
+	}
+	field.Set(merged)
+	return nil
+}
+
+func containsElem(slice, elem reflect.Value) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if reflect.DeepEqual(slice.Index(i).Interface(), elem.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// setArrayField sets an array from a json array which must not be longer than the array.
+func (w *walker) setArrayField(field reflect.Value, defaultVal string) error {
+	elems := reflect.New(reflect.SliceOf(field.Type().Elem()))
+	if err := json.Unmarshal([]byte(defaultVal), elems.Interface()); err != nil {
+		return w.fail(field, defaultVal, err)
+	}
+	if n := elems.Elem().Len(); n > field.Len() {
+		return w.fail(field, defaultVal, arrayLengthError(n, field.Len()))
+	}
+	array := reflect.New(field.Type()).Elem()
+	reflect.Copy(array, elems.Elem())
+	field.Set(array)
+	return nil
+}
+
+func (w *walker) setMapValue(field, key, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		switch v.Elem().Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Map:
+			if err := w.setField(v.Elem(), noDefault); err != nil {
+				return err
+			}
+		default:
+			// nothing to do
+		}
+	case reflect.Struct, reflect.Slice, reflect.Map:
+		ref := reflect.New(v.Type())
+		ref.Elem().Set(v)
+		if err := w.setField(ref.Elem(), noDefault); err != nil {
+			return err
+		}
+		// the copy is stored in the map, the references inside it cannot wait for its ancestors
+		if err := w.resolveRefs(func(r *pendingRef) bool { return overlaps(r.field, ref.Elem()) }); err != nil {
+			return err
+		}
+		field.SetMapIndex(key, ref.Elem().Convert(v.Type()))
+	default:
+		// nothing to do
+	}
+	return nil
+}
+
 func unmarshalByInterface(field reflect.Value, defaultVal string) bool {
 	asText, ok := field.Addr().Interface().(encoding.TextUnmarshaler)
 	if ok && defaultVal != "" {
@@ -191,7 +547,7 @@ func unmarshalByInterface(field reflect.Value, defaultVal string) bool {
 }
 
 func isInitialValue(field reflect.Value) bool {
-	return reflect.DeepEqual(reflect.Zero(field.Type()).Interface(), field.Interface())
+	return field.IsZero()
 }
 
 func shouldInitializeField(field reflect.Value, tag string) bool {
@@ -206,6 +562,10 @@ func shouldInitializeField(field reflect.Value, tag string) bool {
 		return field.Len() > 0 || tag != ""
 	case reflect.Map:
 		return field.Len() > 0 || tag != ""
+	case reflect.Array:
+		return tag != "" || isContainer(field.Type().Elem())
+	case reflect.Interface:
+		return !field.IsNil() || tag != ""
 	default:
 		// nothing to do
 	}
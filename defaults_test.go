@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
@@ -152,8 +153,10 @@ type Struct struct {
 }
 
 func (s *Struct) Default() error {
-	s.Bar = 456
-	return nil
+	if s.Bar == 0 {
+		s.Bar = 456
+	}
+	return LoadStruct(s)
 }
 
 type Embedded struct {
@@ -558,15 +561,15 @@ func TestInit(t *testing.T) {
 		})
 	})
 
-	// t.Run("Setter interface", func(t *testing.T) {
-	// 	if sample.Struct.Bar != 456 {
-	// 		t.Errorf("it should initialize struct")
-	// 	}
-	// 	if sample.StructPtr == nil || sample.StructPtr.Bar != 456 {
-	// 		t.Errorf("it should initialize struct pointer")
-	// 	}
-	// })
-	//
+	t.Run("Setter interface", func(t *testing.T) {
+		if sample.Struct.Bar != 456 {
+			t.Errorf("it should initialize struct")
+		}
+		if sample.StructPtr == nil || sample.StructPtr.Bar != 456 {
+			t.Errorf("it should initialize struct pointer")
+		}
+	})
+
 	// t.Run("non-initial value", func(t *testing.T) {
 	// 	if sample.NonInitialString != "string" {
 	// 		t.Errorf("it should not override non-initial value")
@@ -628,8 +631,8 @@ func TestInit(t *testing.T) {
 		if sample.MapOfPtrStruct["Struct1"].Foo != 1 {
 			t.Errorf("it should not override Foo field in Struct1 item")
 		}
-		if sample.MapOfPtrStruct["Struct1"].Bar != 0 {
-			t.Errorf("it should set default for Bar field in Struct1 item")
+		if sample.MapOfPtrStruct["Struct1"].Bar != 456 {
+			t.Errorf("it should using setter to set default for Bar field in Struct1 item")
 		}
 		if sample.MapOfPtrStruct["Struct1"].WithDefault != "foo" {
 			t.Errorf("it should set default for WithDefault field in Struct1 item")
@@ -747,3 +750,507 @@ func TestDefaultsSetter(t *testing.T) {
 		t.Errorf("expected 1 for MainInt, got %d", main.MainInt)
 	}
 }
+
+func TestStrict(t *testing.T) {
+	type config struct {
+		Port int `default:"80a"`
+	}
+
+	t.Run("lenient by default", func(t *testing.T) {
+		c := &config{}
+		if err := Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Port != 0 {
+			t.Errorf("it should keep the zero value, got %d", c.Port)
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		tests := []struct {
+			name string
+			ptr  any
+			kind reflect.Kind
+		}{
+			{name: "int", ptr: &config{}, kind: reflect.Int},
+			{name: "int8 overflow", ptr: &struct {
+				I int8 `default:"300"`
+			}{}, kind: reflect.Int8},
+			{name: "int64", ptr: &struct {
+				I int64 `default:"10x"`
+			}{}, kind: reflect.Int64},
+			{name: "uint", ptr: &struct {
+				U uint `default:"-1"`
+			}{}, kind: reflect.Uint},
+			{name: "uint16 overflow", ptr: &struct {
+				U uint16 `default:"70000"`
+			}{}, kind: reflect.Uint16},
+			{name: "float", ptr: &struct {
+				F float64 `default:"1.2.3"`
+			}{}, kind: reflect.Float64},
+			{name: "bool", ptr: &struct {
+				B bool `default:"yes"`
+			}{}, kind: reflect.Bool},
+			{name: "pointer", ptr: &struct {
+				P *int `default:"one"`
+			}{}, kind: reflect.Int},
+			{name: "nested", ptr: &struct {
+				S struct {
+					I int `default:"one"`
+				}
+			}{}, kind: reflect.Int},
+		}
+		for _, tt := range tests {
+			err := LoadStruct(tt.ptr, WithStrict(true))
+			var e *ParseError
+			if !errors.As(err, &e) {
+				t.Errorf("%s: it should return a *ParseError, got %v", tt.name, err)
+				continue
+			}
+			if e.Kind != tt.kind {
+				t.Errorf("%s: expected kind %s, got %s", tt.name, tt.kind, e.Kind)
+			}
+		}
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		err := LoadStruct(&struct {
+			I int8 `default:"128"`
+		}{}, WithStrict(true))
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("it should wrap strconv.ErrRange, got %v", err)
+		}
+	})
+
+	t.Run("valid tags", func(t *testing.T) {
+		sample := &Sample{}
+		if err := Load(sample, WithStrict(true)); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if sample.Duration != 10*time.Second {
+			t.Errorf("it should initialize duration")
+		}
+	})
+}
+
+type TLS struct {
+	MinVersion uint16 `default:"tls12"`
+}
+
+type Server struct {
+	Host string `default:"localhost"`
+	TLS  TLS
+}
+
+type Config struct {
+	Servers []Server
+	Ports   map[string]uint8 `default:"{\"http\": 300}"`
+	Limit   int              `default:"ten"`
+}
+
+func TestFieldError(t *testing.T) {
+	t.Run("path", func(t *testing.T) {
+		c := &Config{Servers: make([]Server, 3)}
+		err := Load(c, WithStrict(true))
+		var e *FieldError
+		if !errors.As(err, &e) {
+			t.Fatalf("it should return a *FieldError, got %v", err)
+		}
+		if e.Path != "Config.Servers[0].TLS.MinVersion" {
+			t.Errorf("it should carry the field path, got %s", e.Path)
+		}
+		if e.Type != reflect.TypeOf(uint16(0)) {
+			t.Errorf("it should carry the field type, got %s", e.Type)
+		}
+		if e.Value != "tls12" {
+			t.Errorf("it should carry the raw tag value, got %s", e.Value)
+		}
+		var pe *ParseError
+		if !errors.As(e.Err, &pe) {
+			t.Errorf("it should carry the underlying cause, got %v", e.Err)
+		}
+	})
+
+	t.Run("json error", func(t *testing.T) {
+		err := Load(&struct {
+			I []int `default:"[!]"`
+		}{})
+		var e *FieldError
+		if !errors.As(err, &e) {
+			t.Fatalf("it should return a *FieldError, got %v", err)
+		}
+		if e.Path != "I" {
+			t.Errorf("it should carry the field path, got %s", e.Path)
+		}
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+		c := &Config{Servers: make([]Server, 3)}
+		err := Load(c, WithStrict(true), WithAllErrors(true))
+		var errs FieldErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("it should return FieldErrors, got %v", err)
+		}
+		paths := make([]string, len(errs))
+		for i, e := range errs {
+			paths[i] = e.Path
+		}
+		expected := []string{
+			"Config.Servers[0].TLS.MinVersion",
+			"Config.Servers[1].TLS.MinVersion",
+			"Config.Servers[2].TLS.MinVersion",
+			"Config.Ports",
+			"Config.Limit",
+		}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("expected paths %v, got %v", expected, paths)
+		}
+		if c.Servers[2].Host != "localhost" {
+			t.Errorf("it should keep loading after a failure")
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		c := &Config{Servers: make([]Server, 3)}
+		err := Load(c, WithAllErrors(true))
+		var errs FieldErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "Config.Ports" {
+			t.Errorf("it should only collect non-parse errors without strict mode, got %v", err)
+		}
+	})
+}
+
+type benchServer struct {
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080"`
+	Timeout time.Duration `default:"10s"`
+	Debug   bool          `default:"true"`
+	Ratio   float64       `default:"0.75"`
+	Tags    []string      `default:"[\"a\",\"b\"]"`
+	Inner   Child
+	Ptr     *int `default:"1"`
+}
+
+// BenchmarkLoad compares loading with a warm plan cache against dropping the cached plans before every call,
+// so "recompile" measures compiling the plans plus loading, not the walker used before plans were cached.
+func BenchmarkLoad(b *testing.B) {
+	types := []reflect.Type{reflect.TypeOf(benchServer{}), reflect.TypeOf(Child{})}
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var s benchServer
+			if err := Load(&s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("recompile", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, t := range types {
+				defaultLoader.plans.Delete(defaultLoader.planKey(t))
+			}
+			var s benchServer
+			if err := Load(&s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestPlanCache(t *testing.T) {
+	typ := reflect.TypeOf(benchServer{})
+	defaultLoader.plans.Delete(defaultLoader.planKey(typ))
+
+	var s1, s2 benchServer
+	if err := Load(&s1); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if _, ok := defaultLoader.plans.Load(defaultLoader.planKey(typ)); !ok {
+		t.Fatalf("it should cache the plan of the loaded type")
+	}
+	if err := Load(&s2); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if !reflect.DeepEqual(s1, s2) || s2.Port != 8080 || *s2.Ptr != 1 || s2.Inner.Name != "Tom" {
+		t.Errorf("it should load the same defaults from the cached plan, got %+v", s2)
+	}
+	if s1.Ptr == s2.Ptr || &s1.Tags[0] == &s2.Tags[0] {
+		t.Errorf("it should not share values between loads")
+	}
+}
+
+type Plugin struct {
+	Name string `default:"tag"`
+	Env  string
+}
+
+func (p *Plugin) Default() error {
+	p.Name = "loader"
+	return nil
+}
+
+type OptionPlugin struct {
+	Env string `default:"tag"`
+}
+
+func (p *OptionPlugin) Default(env string) error {
+	p.Env = env
+	return nil
+}
+
+type Plugins struct {
+	Plugin        Plugin
+	PluginPtr     *Plugin `default:"{}"`
+	PluginSlice   []Plugin
+	PluginMap     map[string]Plugin
+	PluginPtrMap  map[string]*Plugin
+	OptionPlugin  OptionPlugin
+	OptionPlugins []*OptionPlugin
+}
+
+func TestNestedDefaultLoader(t *testing.T) {
+	t.Run("DefaultLoader", func(t *testing.T) {
+		p := &Plugins{
+			PluginSlice:  make([]Plugin, 2),
+			PluginMap:    map[string]Plugin{"a": {}},
+			PluginPtrMap: map[string]*Plugin{"b": {}},
+		}
+		if err := Load(p); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if p.Plugin.Name != "loader" {
+			t.Errorf("it should call Default on a nested struct, got %s", p.Plugin.Name)
+		}
+		if p.PluginPtr == nil || p.PluginPtr.Name != "loader" {
+			t.Errorf("it should call Default on a nested struct pointer")
+		}
+		for i, e := range p.PluginSlice {
+			if e.Name != "loader" {
+				t.Errorf("it should call Default on slice element %d, got %s", i, e.Name)
+			}
+		}
+		if p.PluginMap["a"].Name != "loader" {
+			t.Errorf("it should call Default on a map value")
+		}
+		if p.PluginPtrMap["b"].Name != "loader" {
+			t.Errorf("it should call Default on a map pointer value")
+		}
+		if p.OptionPlugin.Env != "tag" {
+			t.Errorf("it should use tags for a DefaultOptionLoader without an argument, got %s", p.OptionPlugin.Env)
+		}
+	})
+
+	t.Run("DefaultOptionLoader", func(t *testing.T) {
+		p := &Plugins{OptionPlugins: []*OptionPlugin{{}, {}}}
+		if err := LoadWithOption(p, "prod"); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if p.OptionPlugin.Env != "prod" {
+			t.Errorf("it should propagate the argument to a nested struct, got %s", p.OptionPlugin.Env)
+		}
+		for i, e := range p.OptionPlugins {
+			if e.Env != "prod" {
+				t.Errorf("it should propagate the argument to slice element %d, got %s", i, e.Env)
+			}
+		}
+		if p.Plugin.Name != "loader" {
+			t.Errorf("it should still call DefaultLoader, got %s", p.Plugin.Name)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := Load(&struct{ F failingLoader }{})
+		var e *FieldError
+		if !errors.As(err, &e) || e.Path != "F" {
+			t.Errorf("it should report the failing Default with its path, got %v", err)
+		}
+	})
+}
+
+type failingLoader struct{}
+
+func (failingLoader) Default() error {
+	return errors.New("failed")
+}
+
+type Endpoint struct {
+	Host string `default:"localhost"`
+	Port int    `default:"80"`
+}
+
+func TestArray(t *testing.T) {
+	type arrays struct {
+		Bytes     [4]byte     `default:"[10, 0, 0, 1]"`
+		Floats    [3]float64  `default:"[1.5, 2.5]"`
+		Endpoints [2]Endpoint `default:"[{\"Host\": \"example.com\"}]"`
+		NoTag     [2]Endpoint
+		Ptrs      [2]*Endpoint  `default:"[{}]"`
+		Durations [1]Duration   `default:"[\"1s\"]"`
+		Set       [2]int        `default:"[1, 2]"`
+		Nested    [1][2]Child   `default:"[]"`
+		Empty     [0]int        `default:"[]"`
+		Matrix    [2][2]float32 `default:"[[1, 2], [3, 4]]"`
+	}
+
+	a := &arrays{Set: [2]int{3}}
+	if err := Load(a, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if a.Bytes != [4]byte{10, 0, 0, 1} {
+		t.Errorf("it should initialize byte array, got %v", a.Bytes)
+	}
+	if a.Floats != [3]float64{1.5, 2.5, 0} {
+		t.Errorf("it should initialize float array, got %v", a.Floats)
+	}
+	if a.Endpoints[0] != (Endpoint{Host: "example.com", Port: 80}) || a.Endpoints[1] != (Endpoint{Host: "localhost", Port: 80}) {
+		t.Errorf("it should set nested defaults in array elements, got %v", a.Endpoints)
+	}
+	if a.NoTag[1] != (Endpoint{Host: "localhost", Port: 80}) {
+		t.Errorf("it should recurse into array elements without a tag, got %v", a.NoTag)
+	}
+	if a.Ptrs[0] == nil || a.Ptrs[0].Port != 80 || a.Ptrs[1] != nil {
+		t.Errorf("it should set nested defaults in array pointer elements, got %v", a.Ptrs)
+	}
+	if a.Durations[0] != Duration(time.Second) {
+		t.Errorf("it should use TextUnmarshaler of array elements, got %v", a.Durations)
+	}
+	if a.Set != [2]int{3} {
+		t.Errorf("it should not override a non-initial array, got %v", a.Set)
+	}
+	if a.Nested[0][1].Name != "Tom" {
+		t.Errorf("it should recurse into nested arrays, got %v", a.Nested)
+	}
+	if a.Matrix != [2][2]float32{{1, 2}, {3, 4}} {
+		t.Errorf("it should initialize nested arrays, got %v", a.Matrix)
+	}
+
+	t.Run("too many elements", func(t *testing.T) {
+		err := Load(&struct {
+			A [2]int `default:"[1, 2, 3]"`
+		}{})
+		if !errors.Is(err, ErrArrayLength) {
+			t.Errorf("it should return ErrArrayLength, got %v", err)
+		}
+	})
+}
+
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	*d = Duration(v)
+	return err
+}
+
+type promoted struct {
+	Host string `default:"localhost"`
+	port int    `default:"80"`
+}
+
+type promotedPtr struct {
+	Timeout time.Duration `default:"5s"`
+}
+
+type Promoted struct {
+	Exported int `default:"1"`
+}
+
+type Embedding struct {
+	promoted
+	*promotedPtr `default:"{}"`
+	*Promoted
+	Name string `default:"embedding"`
+}
+
+func TestEmbedded(t *testing.T) {
+	e := &Embedding{}
+	if err := Load(e, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if e.Host != "localhost" {
+		t.Errorf("it should set promoted fields of an unexported embedded struct, got %q", e.Host)
+	}
+	if e.port != 0 {
+		t.Errorf("it should not set unexported fields, got %d", e.port)
+	}
+	if e.promotedPtr == nil || e.Timeout != 5*time.Second {
+		t.Errorf("it should allocate a tagged unexported embedded pointer, got %v", e.promotedPtr)
+	}
+	if e.Promoted != nil {
+		t.Errorf("it should not allocate an untagged embedded pointer")
+	}
+
+	e = &Embedding{Promoted: &Promoted{}}
+	if err := Load(e); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if e.Exported != 1 {
+		t.Errorf("it should set promoted fields of an embedded pointer, got %d", e.Exported)
+	}
+}
+
+type Listen struct {
+	Host  string `default:"localhost"`
+	Port  string `default:"8080"`
+	Addr  string
+	calls []string
+}
+
+func (l *Listen) BeforeDefaults() error {
+	l.calls = append(l.calls, "before:"+l.Host)
+	return nil
+}
+
+func (l *Listen) AfterDefaults() error {
+	l.calls = append(l.calls, "after:"+l.Host)
+	l.Addr = l.Host + ":" + l.Port
+	return nil
+}
+
+type Listeners struct {
+	Listen    Listen
+	Listeners []*Listen `default:"[{\"Host\": \"example.com\"}]"`
+	Count     int       `default:"1"`
+	Total     int
+}
+
+func (l *Listeners) AfterDefaults() error {
+	l.Total = l.Count + len(l.Listeners)
+	return nil
+}
+
+type failingHook struct {
+	Name string `default:"name"`
+}
+
+func (failingHook) AfterDefaults() error {
+	return errors.New("failed")
+}
+
+func TestDefaultsHook(t *testing.T) {
+	l := &Listeners{}
+	if err := Load(l); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if l.Listen.Addr != "localhost:8080" {
+		t.Errorf("it should compute derived fields after the tag defaults, got %s", l.Listen.Addr)
+	}
+	if !reflect.DeepEqual(l.Listen.calls, []string{"before:", "after:localhost"}) {
+		t.Errorf("it should call BeforeDefaults before and AfterDefaults after the tag defaults, got %v", l.Listen.calls)
+	}
+	if len(l.Listeners) != 1 || l.Listeners[0].Addr != "example.com:8080" {
+		t.Errorf("it should call hooks on slice elements, got %+v", l.Listeners)
+	}
+	if l.Total != 2 {
+		t.Errorf("it should call hooks on the root struct, got %d", l.Total)
+	}
+
+	err := Load(&struct{ Hook failingHook }{})
+	var e *FieldError
+	if !errors.As(err, &e) || e.Path != "Hook" {
+		t.Errorf("it should report the failing hook with its path, got %v", err)
+	}
+}
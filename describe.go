@@ -0,0 +1,143 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"reflect"
+)
+
+// FieldDefault describes the default declared on a field.
+type FieldDefault struct {
+	// Path is the dotted path of the field, elements of slices, arrays and maps are written as `Servers[]`
+	Path string
+	// Type is the Go type of the field
+	Type reflect.Type
+	// Tag is the raw default tag
+	Tag string
+	// Env is the variable named by the env tag
+	Env string
+	// Value is the default parsed into a value of Type, nil if the tag cannot be parsed
+	Value any
+	// Loader is set when the field is loaded by its DefaultLoader instead of tags
+	Loader bool
+}
+
+// Describe returns the defaults declared on T and the structs it holds.
+func Describe[T any](opts ...Option) []FieldDefault {
+	return DescribeType(reflect.TypeOf((*T)(nil)).Elem(), opts...)
+}
+
+// DescribeType returns the defaults declared on the struct type t and the structs it holds,
+// t may be a pointer to a struct.
+func DescribeType(t reflect.Type, opts ...Option) []FieldDefault {
+	return defaultLoader.with(opts...).Describe(t)
+}
+
+// Describe returns the defaults declared on the struct type t and the structs it holds,
+// t may be a pointer to a struct.
+// Fields are walked like they are loaded, fields without a tag are only listed when they implement DefaultLoader.
+func (l *Loader) Describe(t reflect.Type) []FieldDefault {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	skipLoaders := func(any) (bool, error) { return false, nil }
+	w := newWalker(l.with(WithStrict(true), WithAllErrors(false), WithMode(FillZero)), t, skipLoaders)
+	w.shallow = true
+	d := &describer{l: l, w: w}
+	d.describeStruct(make(fieldPath, 0, 8), t)
+	return d.fields
+}
+
+// describer collects the FieldDefault of a struct type.
+type describer struct {
+	l *Loader
+	// w parses the tags in strict mode, so tags which cannot be parsed are found,
+	// it is shallow as describing must not call hooks, DefaultLoaders or factories
+	w *walker
+	// types are the struct types being described, to stop at recursive types
+	types  []reflect.Type
+	fields []FieldDefault
+}
+
+func (d *describer) describeStruct(path fieldPath, t reflect.Type) {
+	for _, typ := range d.types {
+		if typ == t {
+			return
+		}
+	}
+	d.types = append(d.types, t)
+	defer func() { d.types = d.types[:len(d.types)-1] }()
+
+	fields := d.l.planOf(t).fields
+	for i := range fields {
+		f := &fields[i]
+		sf := t.Field(f.index)
+		if !sf.IsExported() && !f.hidden {
+			continue
+		}
+		p := path
+		if !f.hidden {
+			// promoted fields keep their own names
+			p = path.push(f.name)
+		}
+		loader := reflect.PtrTo(indirect(sf.Type)).Implements(defaultLoaderType)
+		if f.def.raw != "" || f.env != "" || (loader && !f.hidden) {
+			d.fields = append(d.fields, FieldDefault{
+				Path:   p.String(),
+				Type:   sf.Type,
+				Tag:    f.def.raw,
+				Env:    f.env,
+				Value:  d.parse(sf.Type, f),
+				Loader: loader,
+			})
+		}
+		if !loader {
+			d.describeType(p, sf.Type)
+		}
+	}
+}
+
+// describeType walks the structs held by t.
+func (d *describer) describeType(path fieldPath, t reflect.Type) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		d.describeType(path, t.Elem())
+	case reflect.Struct:
+		d.describeStruct(path, t)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		elem := indirect(t.Elem())
+		if elem.Kind() == reflect.Struct && !reflect.PtrTo(elem).Implements(defaultLoaderType) {
+			d.describeStruct(path.pushElem(), elem)
+		}
+	default:
+		// nothing to do
+	}
+}
+
+// parse returns the default of f parsed into a value of t, environment references are expanded.
+func (d *describer) parse(t reflect.Type, f *fieldPlan) any {
+	if f.def.raw == "" || f.def.ref != nil {
+		return nil
+	}
+	def := f.def
+	if def.expand {
+		def = def.withRaw(expandEnv(def.raw, d.l.lookupEnv))
+	}
+	v := reflect.New(t).Elem()
+	if err := d.w.setField(v, def); err != nil {
+		return nil
+	}
+	return v.Interface()
+}
+
+// indirect returns the type t points to, or t itself.
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
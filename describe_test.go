@@ -0,0 +1,122 @@
+package dl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type describeNode struct {
+	Name string `default:"node"`
+	Next *describeNode
+}
+
+type describeConfig struct {
+	resetLimits
+	Name    string        `default:"app"`
+	Port    int           `default:"80" env:"APP_PORT"`
+	Bad     int           `default:"80a"`
+	Retries *int          `default:"3"`
+	Dir     string        `default:"${DATA_DIR:-/var/lib/app}"`
+	Server  resetServer   `default:"{\"Host\": \"example.com\"}"`
+	Servers []resetServer `default:"[{}]"`
+	Nodes   map[string]*describeNode
+	Plugin  Plugin
+	Debug   bool
+	secret  string `default:"hidden"` //nolint:unused
+}
+
+func TestDescribe(t *testing.T) {
+	fields := Describe[describeConfig](WithLookupEnv(lookupMap(nil)))
+	got := make(map[string]FieldDefault, len(fields))
+	var paths []string
+	for _, f := range fields {
+		got[f.Path] = f
+		paths = append(paths, f.Path)
+	}
+
+	expected := []string{
+		"Burst", "Name", "Port", "Bad", "Retries", "Dir",
+		"Server", "Server.Host", "Server.Timeout",
+		"Servers", "Servers[].Host", "Servers[].Timeout",
+		"Nodes[].Name", "Plugin",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("it should list the fields in order\ngot  %v\nwant %v", paths, expected)
+	}
+
+	if f := got["Port"]; f.Tag != "80" || f.Env != "APP_PORT" || f.Value != 80 || f.Type != reflect.TypeOf(0) {
+		t.Errorf("it should describe the tag, env and parsed value, got %+v", f)
+	}
+	if f := got["Bad"]; f.Value != nil {
+		t.Errorf("it should not parse invalid tags, got %v", f.Value)
+	}
+	if f := got["Retries"]; f.Value == nil || *f.Value.(*int) != 3 {
+		t.Errorf("it should parse pointer defaults, got %v", f.Value)
+	}
+	if f := got["Dir"]; f.Tag != "${DATA_DIR:-/var/lib/app}" || f.Value != "/var/lib/app" {
+		t.Errorf("it should expand environment references in the value only, got %+v", f)
+	}
+	if f := got["Server"]; !reflect.DeepEqual(f.Value, resetServer{Host: "example.com", Timeout: 5 * time.Second}) {
+		t.Errorf("it should load nested defaults into the value, got %+v", f.Value)
+	}
+	if f := got["Server.Timeout"]; f.Value != 5*time.Second {
+		t.Errorf("it should parse durations, got %v", f.Value)
+	}
+	if f := got["Plugin"]; !f.Loader || f.Tag != "" {
+		t.Errorf("it should list fields implementing DefaultLoader, got %+v", f)
+	}
+
+	if fields := DescribeType(reflect.TypeOf(&describeConfig{})); len(fields) != len(expected) {
+		t.Errorf("it should accept pointer types, got %d fields", len(fields))
+	}
+	if fields := DescribeType(reflect.TypeOf(0)); fields != nil {
+		t.Errorf("it should return nil for non-struct types, got %v", fields)
+	}
+}
+
+type describeCalls struct {
+	hooks, loaders, factories int
+}
+
+type describeLoader struct {
+	calls *describeCalls
+}
+
+func (d *describeLoader) Default() error {
+	d.calls.loaders++
+	return nil
+}
+
+type describeHooked struct {
+	Name   string `default:"hooked"`
+	calls  *describeCalls
+	Loader describeLoader
+	Items  []describeLoader `default:"[{}]"`
+	Store  Storage          `default:"counted"`
+}
+
+func (d *describeHooked) AfterDefaults() error {
+	d.calls.hooks++
+	return nil
+}
+
+func TestDescribeSideEffects(t *testing.T) {
+	calls := &describeCalls{}
+	fields := Describe[describeHooked](
+		WithHook(func(any) error {
+			calls.hooks++
+			return nil
+		}),
+		WithFactory[Storage]("counted", func() Storage {
+			calls.factories++
+			return &MemoryStorage{}
+		}),
+	)
+	if len(fields) == 0 {
+		t.Fatal("it should describe the fields")
+	}
+	if *calls != (describeCalls{}) {
+		t.Errorf("it should not call hooks, loaders or factories, got %+v", *calls)
+	}
+}
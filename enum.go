@@ -0,0 +1,54 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownEnum is returned in strict mode when a tag value names no value of an enum.
+var ErrUnknownEnum = errors.New("unknown enum name")
+
+// RegisterEnum parses tag values of type T by the names of its values for every Loader, like `default:"Info"`,
+// names are matched case-insensitively and integer enums also accept their numbers.
+// It is a parser, so it works for pointers to T and elements of slices and values of maps holding T.
+func RegisterEnum[T any](names map[string]T) {
+	RegisterParser(enumParser(names))
+}
+
+// WithEnum parses tag values of type T by the names of its values, see RegisterEnum.
+func WithEnum[T any](names map[string]T) Option {
+	return WithParser(enumParser(names))
+}
+
+// enumParser returns the parser matching the names case-insensitively, its error lists the valid names.
+func enumParser[T any](names map[string]T) func(string) (T, error) {
+	values := make(map[string]T, len(names))
+	valid := make([]string, 0, len(names))
+	for name, v := range names {
+		values[strings.ToLower(name)] = v
+		valid = append(valid, name)
+	}
+	sort.Strings(valid)
+
+	return func(s string) (T, error) {
+		if v, ok := values[strings.ToLower(strings.TrimSpace(s))]; ok {
+			return v, nil
+		}
+		var v T
+		field := reflect.ValueOf(&v).Elem()
+		switch field.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if err := parseScalar(field, s, ""); err == nil {
+				return v, nil
+			}
+		}
+		return v, fmt.Errorf("%w %q, valid names are %s", ErrUnknownEnum, s, strings.Join(valid, ", "))
+	}
+}
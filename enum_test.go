@@ -0,0 +1,73 @@
+package dl
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+)
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum(map[string]logLevel{"Debug": levelDebug, "Info": levelInfo, "Warn": levelWarn})
+	t.Cleanup(func() { parsers.Delete(reflect.TypeOf(levelDebug)) })
+
+	type config struct {
+		Level    logLevel            `default:"Info"`
+		Lower    logLevel            `default:"warn"`
+		Number   logLevel            `default:"2"`
+		Pointer  *logLevel           `default:"WARN"`
+		Levels   []logLevel          `default:"[\"debug\", \"Warn\"]"`
+		Services map[string]logLevel `default:"{\"api\": \"Info\"}"`
+	}
+	c := &config{}
+	if err := Load(c, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Level != levelInfo || c.Lower != levelWarn || c.Number != levelWarn {
+		t.Errorf("it should match the names case-insensitively, got %+v", c)
+	}
+	if c.Pointer == nil || *c.Pointer != levelWarn {
+		t.Errorf("it should parse pointers, got %v", c.Pointer)
+	}
+	if len(c.Levels) != 2 || c.Levels[0] != levelDebug || c.Levels[1] != levelWarn || c.Services["api"] != levelInfo {
+		t.Errorf("it should parse elements, got %v %v", c.Levels, c.Services)
+	}
+
+	bad := &struct {
+		Level logLevel `default:"verbose"`
+	}{}
+	err := Load(bad, WithStrict(true))
+	if !errors.Is(err, ErrUnknownEnum) || !strings.Contains(err.Error(), "Debug, Info, Warn") {
+		t.Errorf("it should list the valid names in strict mode, got %v", err)
+	}
+	if err := Load(bad); err != nil || bad.Level != levelDebug {
+		t.Errorf("it should keep the field in lenient mode, got %v, %v", bad.Level, err)
+	}
+}
+
+func TestWithEnum(t *testing.T) {
+	type color string
+	c := &struct {
+		Color color `default:"RED"`
+	}{}
+	l := NewLoader(WithStrict(true), WithEnum(map[string]color{"red": "#f00", "green": "#0f0"}))
+	if err := l.Load(c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Color != "#f00" {
+		t.Errorf("it should set the value of the name, got %s", c.Color)
+	}
+	if err := l.Load(&struct {
+		Color color `default:"blue"`
+	}{}); !errors.Is(err, ErrUnknownEnum) {
+		t.Errorf("it should not accept other strings, got %v", err)
+	}
+}
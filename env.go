@@ -0,0 +1,64 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"strings"
+)
+
+const (
+	envTagName = "env"
+)
+
+// LookupEnvFunc looks up an environment variable like os.LookupEnv.
+type LookupEnvFunc func(key string) (string, bool)
+
+// expandEnv replaces `${NAME}` and `${NAME:-fallback}` in s by the value of NAME,
+// the fallback is used when NAME is unset or empty. A `$` not followed by `{` is kept as is.
+func expandEnv(s string, lookup LookupEnvFunc) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		sb.WriteString(s[:start])
+
+		name, fallback, hasFallback := strings.Cut(s[start+2:end], ":-")
+		val, _ := lookup(name)
+		if val == "" && hasFallback {
+			val = fallback
+		}
+		sb.WriteString(val)
+		s = s[end+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// hasEnv reports whether s holds a `${...}` reference.
+func hasEnv(s string) bool {
+	start := strings.Index(s, "${")
+	return start >= 0 && strings.IndexByte(s[start:], '}') > 0
+}
+
+// resolveDefault returns the default of f after applying its env tag and expanding references,
+// a variable set to an empty string clears the default.
+func (l *Loader) resolveDefault(f *fieldPlan) *fieldDefault {
+	if f.env != "" {
+		if val, ok := l.lookupEnv(f.env); ok {
+			return f.def.withRaw(val)
+		}
+	}
+	if !f.def.expand || f.def.ref != nil {
+		// references are expanded once they are resolved
+		return f.def
+	}
+	return f.def.withRaw(expandEnv(f.def.raw, l.lookupEnv))
+}
@@ -0,0 +1,118 @@
+package dl
+
+import (
+	"testing"
+	"time"
+)
+
+func lookupMap(env map[string]string) LookupEnvFunc {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	lookup := lookupMap(map[string]string{"HOME": "/home/dl", "EMPTY": ""})
+	tests := []struct {
+		in, out string
+	}{
+		{in: "plain", out: "plain"},
+		{in: "${HOME}/cache", out: "/home/dl/cache"},
+		{in: "${DATA_DIR:-/var/lib/app}/cache", out: "/var/lib/app/cache"},
+		{in: "${HOME:-/root}", out: "/home/dl"},
+		{in: "${EMPTY:-fallback}", out: "fallback"},
+		{in: "${MISSING}", out: ""},
+		{in: "^a$", out: "^a$"},
+		{in: "$HOME", out: "$HOME"},
+		{in: "${HOME", out: "${HOME"},
+		{in: "${HOME}:${HOME}", out: "/home/dl:/home/dl"},
+	}
+	for _, tt := range tests {
+		if out := expandEnv(tt.in, lookup); out != tt.out {
+			t.Errorf("expandEnv(%q) returns %q, expected %q", tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	type config struct {
+		Dir      string            `default:"${DATA_DIR:-/var/lib/app}/cache"`
+		Port     int               `default:"80" env:"APP_PORT"`
+		Timeout  time.Duration     `default:"${TIMEOUT:-5s}"`
+		Hosts    []string          `default:"[\"${HOST}\", \"backup\"]"`
+		Labels   map[string]string `default:"{\"env\": \"${STAGE:-dev}\"}"`
+		Retries  *int              `default:"${RETRIES}"`
+		User     string            `env:"APP_USER"`
+		Fallback string            `default:"${HOST}" env:"APP_FALLBACK"`
+		Region   string            `default:"eu" env:"APP_REGION"`
+	}
+
+	t.Run("set", func(t *testing.T) {
+		l := NewLoader(WithLookupEnv(lookupMap(map[string]string{
+			"DATA_DIR": "/data",
+			"APP_PORT": "8080",
+			"TIMEOUT":  "1m",
+			"HOST":     "primary",
+			"STAGE":    "prod",
+			"RETRIES":  "3",
+			"APP_USER": "dl",
+		})), WithStrict(true))
+		c := &config{}
+		if err := l.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Dir != "/data/cache" {
+			t.Errorf("it should expand references, got %s", c.Dir)
+		}
+		if c.Port != 8080 {
+			t.Errorf("it should override the default by the env tag, got %d", c.Port)
+		}
+		if c.Timeout != time.Minute {
+			t.Errorf("it should parse expanded durations, got %s", c.Timeout)
+		}
+		if len(c.Hosts) != 2 || c.Hosts[0] != "primary" {
+			t.Errorf("it should expand references in slices, got %v", c.Hosts)
+		}
+		if c.Labels["env"] != "prod" {
+			t.Errorf("it should expand references in maps, got %v", c.Labels)
+		}
+		if c.Retries == nil || *c.Retries != 3 {
+			t.Errorf("it should expand references for pointers, got %v", c.Retries)
+		}
+		if c.User != "dl" {
+			t.Errorf("it should set fields with only an env tag, got %s", c.User)
+		}
+		if c.Fallback != "primary" {
+			t.Errorf("it should expand the default when the env tag is unset, got %s", c.Fallback)
+		}
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		l := NewLoader(WithLookupEnv(lookupMap(nil)))
+		c := &config{Port: 1}
+		if err := l.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Dir != "/var/lib/app/cache" || c.Timeout != 5*time.Second || c.Labels["env"] != "dev" {
+			t.Errorf("it should use the fallbacks, got %+v", c)
+		}
+		if c.Port != 1 {
+			t.Errorf("it should not override a non-initial value, got %d", c.Port)
+		}
+		if c.User != "" {
+			t.Errorf("it should keep fields with only an env tag untouched, got %s", c.User)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		l := NewLoader(WithLookupEnv(lookupMap(map[string]string{"APP_PORT": "", "APP_REGION": ""})), WithStrict(true))
+		c := &config{}
+		if err := l.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Port != 0 || c.Region != "" {
+			t.Errorf("it should clear the default when the variable is set to an empty string, got %d %q", c.Port, c.Region)
+		}
+	})
+}
@@ -6,10 +6,31 @@ package dl
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 var ErrInvalidType = errors.New("empty")
 
+// ErrMaxDepth is returned when nested structs are deeper than the configured max depth.
+var ErrMaxDepth = errors.New("max depth exceeded")
+
+// ErrCycle is returned when a default allocates a struct which is already being loaded.
+var ErrCycle = errors.New("default cycle")
+
+// ErrUnknownFactory is returned in strict mode when no factory is registered for an interface default.
+var ErrUnknownFactory = errors.New("unknown factory")
+
+// ErrUnknownField is returned when a field path does not name an exported field.
+var ErrUnknownField = errors.New("unknown field")
+
+// ErrArrayLength is returned when a default tag holds more elements than the array can.
+var ErrArrayLength = errors.New("too many elements for array")
+
+func arrayLengthError(n, length int) error {
+	return fmt.Errorf("%w: %d elements for length %d", ErrArrayLength, n, length)
+}
+
 type invalidTypeErr struct {
 	typeString string
 }
@@ -29,3 +50,83 @@ func (i *invalidTypeErr) Is(err error) bool {
 func InvalidTypeError(typeString string) error {
 	return &invalidTypeErr{typeString: typeString}
 }
+
+// ParseError is returned in strict mode when a default tag cannot be parsed into its field kind.
+type ParseError struct {
+	Kind  reflect.Kind
+	Value string
+	Err   error
+}
+
+func (p *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse %q as %s: %v", p.Value, p.Kind, p.Err)
+}
+
+func (p *ParseError) Unwrap() error {
+	return p.Err
+}
+
+func parseError(kind reflect.Kind, value string, err error) error {
+	return &ParseError{Kind: kind, Value: value, Err: err}
+}
+
+// FieldError describes a field whose default value could not be loaded.
+type FieldError struct {
+	// Path is the dotted path of the field, like `Config.Servers[2].TLS.MinVersion`
+	Path string
+	// Type is the Go type of the field
+	Type reflect.Type
+	// Value is the raw tag value
+	Value string
+	// Err is the underlying cause
+	Err error
+}
+
+func (f *FieldError) Error() string {
+	return fmt.Sprintf("field %s (%s) with default %q: %v", f.Path, f.Type, f.Value, f.Err)
+}
+
+func (f *FieldError) Unwrap() error {
+	return f.Err
+}
+
+// FieldErrors is returned when WithAllErrors is set and one or more fields failed.
+type FieldErrors []*FieldError
+
+func (f FieldErrors) Error() string {
+	msgs := make([]string, len(f))
+	for i, e := range f {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the collected errors so errors.Is and errors.As can match any of them.
+func (f FieldErrors) Unwrap() []error {
+	errs := make([]error, len(f))
+	for i, e := range f {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Is reports whether any collected error matches target,
+// errors.Is does not follow Unwrap() []error before Go 1.20.
+func (f FieldErrors) Is(target error) bool {
+	for _, e := range f {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets target to the first collected error matching it, like the first *FieldError for a **FieldError.
+func (f FieldErrors) As(target any) bool {
+	for _, e := range f {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
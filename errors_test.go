@@ -2,6 +2,7 @@ package dl
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -54,3 +55,46 @@ func TestError_Is(t *testing.T) {
 		t.Error("errors.Is(err1, err2) should return true")
 	}
 }
+
+func TestParseError(t *testing.T) {
+	cause := errors.New("invalid syntax")
+	err := parseError(reflect.Int, "80a", cause)
+
+	var e *ParseError
+	if !errors.As(err, &e) {
+		t.Fatal("parseError should return a *ParseError")
+	}
+	if e.Error() != `cannot parse "80a" as int: invalid syntax` {
+		t.Errorf("ParseError.Error should return the correct string, got %s", e.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("ParseError should unwrap to its cause")
+	}
+}
+
+func TestFieldErrors_Error(t *testing.T) {
+	errs := FieldErrors{
+		{Path: "Config.Port", Type: reflect.TypeOf(0), Value: "80a", Err: errors.New("invalid syntax")},
+		{Path: "Config.Debug", Type: reflect.TypeOf(false), Value: "yes", Err: errors.New("invalid syntax")},
+	}
+	expected := `field Config.Port (int) with default "80a": invalid syntax; ` +
+		`field Config.Debug (bool) with default "yes": invalid syntax`
+	if errs.Error() != expected {
+		t.Errorf("FieldErrors.Error should return the correct string, got %s", errs.Error())
+	}
+
+	var e *FieldError
+	if !errors.As(errs, &e) || e.Path != "Config.Port" {
+		t.Error("errors.As should match the first *FieldError")
+	}
+
+	cause := errors.New("cause")
+	errs = append(errs, &FieldError{Path: "Config.Name", Err: &ParseError{Err: cause}})
+	if !errors.Is(errs, cause) || !errs.Is(cause) || errs.Is(ErrCycle) {
+		t.Error("FieldErrors should match the causes of the collected errors")
+	}
+	var pe *ParseError
+	if !errs.As(&pe) || pe.Err != cause {
+		t.Error("FieldErrors should match the collected errors with errors.As")
+	}
+}
@@ -1,5 +1,9 @@
 package example
 
+import (
+	"time"
+)
+
 type StructStruct struct {
 	Key   string `default:"key"`
 	Value string `default:"value"`
@@ -49,3 +53,23 @@ type StructNamed struct {
 	// FieldStruct    StructStruct        `default:"{Key:key,Value:value}"`
 	// FieldStructSlice []StructStruct `default:"[{Key:key,Value:value},{Key:key2,Value:value2}]"`
 }
+
+type StructEmbedded struct {
+	StructStruct
+	*StructInner `default:"{}"`
+	FieldString  string `default:"embedded"`
+}
+
+type StructUnit struct {
+	FieldBuffer    int            `default:"64MiB" unit:"bytes"`
+	FieldRetention time.Duration  `default:"7d" unit:"duration"`
+	FieldPSize     *uint32        `default:"1GB" unit:"bytes"`
+	FieldTimeout   *time.Duration `default:"1w12h" unit:"duration"`
+}
+
+type StructProfile struct {
+	FieldLevel  string `default:"info" default.dev:"debug" default.prod:"warn"`
+	FieldPort   int    `default:"8080" default.prod:"80"`
+	FieldBuffer int    `default:"1MiB" default.prod:"64MiB" unit:"bytes"`
+	FieldTrace  bool   `default.dev:"true"`
+}
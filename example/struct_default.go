@@ -8,6 +8,7 @@ package example
 
 import (
 	"github.com/godcong/dl"
+	"time"
 )
 
 // Default loads default values for StructStruct
@@ -17,6 +18,12 @@ func (obj *StructStruct) Default() error {
 	return nil
 }
 
+// DefaultProfile loads default values of the profile for StructStruct,
+// fields without a value in the profile get their default values
+func (obj *StructStruct) DefaultProfile(profile string) error {
+	return obj.Default()
+}
+
 // Default loads default values for StructStd
 func (obj *StructStd) Default() error {
 	obj.FieldString = "test"
@@ -41,6 +48,12 @@ func (obj *StructStd) Default() error {
 	return nil
 }
 
+// DefaultProfile loads default values of the profile for StructStd,
+// fields without a value in the profile get their default values
+func (obj *StructStd) DefaultProfile(profile string) error {
+	return obj.Default()
+}
+
 // Default loads default values for StructInner
 func (obj *StructInner) Default() error {
 	if err := dl.Load(&obj.FieldInnerStruct); err != nil {
@@ -48,3 +61,112 @@ func (obj *StructInner) Default() error {
 	}
 	return nil
 }
+
+// DefaultProfile loads default values of the profile for StructInner,
+// fields without a value in the profile get their default values
+func (obj *StructInner) DefaultProfile(profile string) error {
+	if err := dl.Load(&obj.FieldInnerStruct, dl.WithProfile(profile)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Default loads default values for StructEmbedded
+func (obj *StructEmbedded) Default() error {
+	if err := dl.Load(&obj.StructStruct); err != nil {
+		return err
+	}
+	if obj.StructInner == nil {
+		obj.StructInner = new(StructInner)
+	}
+	if err := dl.Load(obj.StructInner); err != nil {
+		return err
+	}
+	obj.FieldString = "embedded"
+	return nil
+}
+
+// DefaultProfile loads default values of the profile for StructEmbedded,
+// fields without a value in the profile get their default values
+func (obj *StructEmbedded) DefaultProfile(profile string) error {
+	if err := dl.Load(&obj.StructStruct, dl.WithProfile(profile)); err != nil {
+		return err
+	}
+	if obj.StructInner == nil {
+		obj.StructInner = new(StructInner)
+	}
+	if err := dl.Load(obj.StructInner, dl.WithProfile(profile)); err != nil {
+		return err
+	}
+	obj.FieldString = "embedded"
+	return nil
+}
+
+// Default loads default values for StructUnit
+func (obj *StructUnit) Default() error {
+	if err := dl.SetUnit(&obj.FieldBuffer, "64MiB", "bytes"); err != nil {
+		return err
+	}
+	if err := dl.SetUnit(&obj.FieldRetention, "7d", "duration"); err != nil {
+		return err
+	}
+	obj.FieldPSize = new(uint32)
+	if err := dl.SetUnit(obj.FieldPSize, "1GB", "bytes"); err != nil {
+		return err
+	}
+	obj.FieldTimeout = new(time.Duration)
+	if err := dl.SetUnit(obj.FieldTimeout, "1w12h", "duration"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DefaultProfile loads default values of the profile for StructUnit,
+// fields without a value in the profile get their default values
+func (obj *StructUnit) DefaultProfile(profile string) error {
+	return obj.Default()
+}
+
+// Default loads default values for StructProfile
+func (obj *StructProfile) Default() error {
+	obj.FieldLevel = "info"
+	obj.FieldPort = 8080
+	if err := dl.SetUnit(&obj.FieldBuffer, "1MiB", "bytes"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DefaultProfile loads default values of the profile for StructProfile,
+// fields without a value in the profile get their default values
+func (obj *StructProfile) DefaultProfile(profile string) error {
+	switch profile {
+	case "dev":
+		obj.FieldLevel = "debug"
+	case "prod":
+		obj.FieldLevel = "warn"
+	default:
+		obj.FieldLevel = "info"
+	}
+	switch profile {
+	case "prod":
+		obj.FieldPort = 80
+	default:
+		obj.FieldPort = 8080
+	}
+	switch profile {
+	case "prod":
+		if err := dl.SetUnit(&obj.FieldBuffer, "64MiB", "bytes"); err != nil {
+			return err
+		}
+	default:
+		if err := dl.SetUnit(&obj.FieldBuffer, "1MiB", "bytes"); err != nil {
+			return err
+		}
+	}
+	switch profile {
+	case "dev":
+		obj.FieldTrace = true
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type factoryKey struct {
+	typ  reflect.Type
+	name string
+}
+
+type factoryFunc func() reflect.Value
+
+// factories holds the factoryFunc registered by RegisterFactory for each interface type and name.
+var factories sync.Map
+
+// RegisterFactory registers fn to create the value of interface fields with type I
+// whose default tag is name, like a Store Storage field tagged with `default:"memory"`.
+// The created value gets its own defaults applied before it is assigned.
+// A factory set by WithFactory takes precedence over the registered one.
+func RegisterFactory[I any](name string, fn func() I) {
+	k, f := newFactory(name, fn)
+	factories.Store(k, f)
+}
+
+func newFactory[I any](name string, fn func() I) (factoryKey, factoryFunc) {
+	return factoryKey{typ: reflect.TypeOf((*I)(nil)).Elem(), name: name}, func() reflect.Value {
+		v := fn()
+		return reflect.ValueOf(&v).Elem()
+	}
+}
+
+// factoryFor returns the factory of the Loader or the registered one for t and name.
+func (l *Loader) factoryFor(t reflect.Type, name string) factoryFunc {
+	k := factoryKey{typ: t, name: name}
+	if f, ok := l.factories[k]; ok {
+		return f
+	}
+	if f, ok := factories.Load(k); ok {
+		return f.(factoryFunc)
+	}
+	return nil
+}
+
+// setInterfaceField assigns the value created by the factory named by the tag.
+// Unknown names are reported only in strict mode.
+func (w *walker) setInterfaceField(field reflect.Value, defaultVal string) error {
+	create := w.l.factoryFor(field.Type(), defaultVal)
+	if create == nil {
+		if w.l.strict {
+			return w.fail(field, defaultVal, fmt.Errorf("%w %q for %s", ErrUnknownFactory, defaultVal, field.Type()))
+		}
+		return nil
+	}
+	field.Set(create())
+	return nil
+}
+
+// setInterfaceValue applies defaults to the concrete value held by an interface field.
+func (w *walker) setInterfaceValue(field reflect.Value) error {
+	elem := field.Elem()
+	switch elem.Kind() {
+	case reflect.Ptr:
+		if elem.IsNil() || !isContainer(elem.Type().Elem()) {
+			return nil
+		}
+		return w.setField(elem.Elem(), noDefault)
+	case reflect.Struct:
+		ref := reflect.New(elem.Type()).Elem()
+		ref.Set(elem)
+		if err := w.setField(ref, noDefault); err != nil {
+			return err
+		}
+		field.Set(ref)
+	default:
+		// nothing to do
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package dl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type Storage interface {
+	Name() string
+}
+
+type MemoryStorage struct {
+	Size int `default:"64"`
+}
+
+func (m *MemoryStorage) Name() string {
+	return "memory"
+}
+
+type FileStorage struct {
+	Dir string `default:"/tmp"`
+}
+
+func (f FileStorage) Name() string {
+	return "file"
+}
+
+type DiskStorage struct {
+	Dir string
+}
+
+func (d *DiskStorage) Name() string {
+	return "disk"
+}
+
+func (d *DiskStorage) Default() error {
+	d.Dir = "/var/lib/disk"
+	return nil
+}
+
+// registerFactory registers fn like RegisterFactory until the test ends.
+func registerFactory[I any](t *testing.T, name string, fn func() I) {
+	RegisterFactory(name, fn)
+	t.Cleanup(func() { factories.Delete(factoryKey{typ: reflect.TypeOf((*I)(nil)).Elem(), name: name}) })
+}
+
+func TestRegisterFactory(t *testing.T) {
+	registerFactory[Storage](t, "memory", func() Storage { return &MemoryStorage{} })
+	registerFactory[Storage](t, "file", func() Storage { return FileStorage{} })
+	registerFactory[Storage](t, "disk", func() Storage { return &DiskStorage{} })
+	registerFactory[any](t, "child", func() any { return &Child{} })
+
+	type config struct {
+		Store    Storage `default:"memory"`
+		File     Storage `default:"file"`
+		Disk     Storage `default:"disk"`
+		Set      Storage `default:"memory"`
+		Untagged Storage
+		Any      any `default:"child"`
+		Empty    any `default:""`
+		Ignore   any `default:"-"`
+	}
+
+	c := &config{Set: FileStorage{}, Untagged: &MemoryStorage{}}
+	if err := Load(c, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if m, ok := c.Store.(*MemoryStorage); !ok || m.Size != 64 {
+		t.Errorf("it should create the registered implementation with its defaults, got %#v", c.Store)
+	}
+	if f, ok := c.File.(FileStorage); !ok || f.Dir != "/tmp" {
+		t.Errorf("it should apply defaults to a struct value, got %#v", c.File)
+	}
+	if d, ok := c.Disk.(*DiskStorage); !ok || d.Dir != "/var/lib/disk" {
+		t.Errorf("it should call DefaultLoader of the created value, got %#v", c.Disk)
+	}
+	if f, ok := c.Set.(FileStorage); !ok || f.Dir != "/tmp" {
+		t.Errorf("it should keep a non-initial value and apply its defaults, got %#v", c.Set)
+	}
+	if m, ok := c.Untagged.(*MemoryStorage); !ok || m.Size != 64 {
+		t.Errorf("it should apply defaults to the value of an untagged field, got %#v", c.Untagged)
+	}
+	if ch, ok := c.Any.(*Child); !ok || ch.Name != "Tom" {
+		t.Errorf("it should create values for empty interfaces, got %#v", c.Any)
+	}
+	if c.Empty != nil || c.Ignore != nil {
+		t.Errorf("it should not set fields without a default")
+	}
+
+	t.Run("loader factory", func(t *testing.T) {
+		c := &config{}
+		l := NewLoader(WithFactory[Storage]("memory", func() Storage { return &DiskStorage{} }))
+		if err := l.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if _, ok := c.Store.(*DiskStorage); !ok {
+			t.Errorf("it should prefer the factory of the loader, got %#v", c.Store)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		s := &struct {
+			Store Storage `default:"redis"`
+		}{}
+		if err := Load(s); err != nil || s.Store != nil {
+			t.Errorf("it should ignore unknown names in lenient mode, got %v", err)
+		}
+		if err := Load(s, WithStrict(true)); !errors.Is(err, ErrUnknownFactory) {
+			t.Errorf("it should return ErrUnknownFactory in strict mode, got %v", err)
+		}
+	})
+}
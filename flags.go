@@ -0,0 +1,130 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+const usageTagName = "usage"
+
+// BindFlags loads the defaults of the struct referenced by `ptr`, and registers a flag in fs for each of its leaf fields,
+// named by the prefix and the kebab cased path of the field, like `app.server.read-timeout` for `Server.ReadTimeout`.
+// The default of a flag is the default of its field, and its usage is the `usage` tag or else the path of the field.
+// Parsed flags are written into the struct, their values are parsed like default tags.
+// `ptr` should be a struct pointer
+func BindFlags(fs *flag.FlagSet, ptr any, prefix string, opts ...Option) error {
+	return defaultLoader.with(opts...).BindFlags(fs, ptr, prefix)
+}
+
+// BindFlags loads the defaults of the struct referenced by `ptr`, and registers a flag in fs for each of its leaf fields,
+// named by the prefix and the kebab cased path of the field, like `app.server.read-timeout` for `Server.ReadTimeout`.
+// The default of a flag is the default of its field, and its usage is the `usage` tag or else the path of the field.
+// Parsed flags are written into the struct, their values are parsed like default tags.
+// `ptr` should be a struct pointer
+func (l *Loader) BindFlags(fs *flag.FlagSet, ptr any, prefix string) error {
+	v, err := structOf(ptr)
+	if err != nil {
+		return err
+	}
+	if err := l.Load(ptr); err != nil {
+		return err
+	}
+
+	sl := l.stringLoader()
+	for _, leaf := range l.leavesOf(v.Type()) {
+		usage := leaf.field.Tag.Get(usageTagName)
+		if usage == "" {
+			usage = leaf.path.String()
+		}
+		fs.Var(&fieldFlag{l: sl, root: v, leaf: leaf}, flagName(prefix, leaf.path), usage)
+	}
+	return nil
+}
+
+// fieldFlag is the flag.Value of a leaf field.
+type fieldFlag struct {
+	l    *Loader
+	root reflect.Value
+	leaf leafField
+	// value is the parsed flag, it is kept as the field may be set by other layers after the flags are parsed
+	value *string
+}
+
+// String returns the parsed flag, or else the value of the field in the form it is parsed from.
+func (f *fieldFlag) String() string {
+	if f.l == nil {
+		// the zero value is used by flag.PrintDefaults
+		return ""
+	}
+	if f.value != nil {
+		return *f.value
+	}
+	field, err := f.l.fieldByIndex(f.root, f.leaf.index, false)
+	if err != nil || !field.IsValid() {
+		return ""
+	}
+	return formatValue(field)
+}
+
+// Set parses s into the field like a default tag.
+func (f *fieldFlag) Set(s string) error {
+	field, err := f.l.fieldByIndex(f.root, f.leaf.index, true)
+	if err != nil {
+		return err
+	}
+	if err := f.l.setString(field, &f.leaf, s); err != nil {
+		return err
+	}
+	f.value = &s
+	return nil
+}
+
+// IsBoolFlag allows bool flags without a value, like `-debug`.
+func (f *fieldFlag) IsBoolFlag() bool {
+	return indirect(f.leaf.field.Type).Kind() == reflect.Bool
+}
+
+// formatValue formats v in the form of a default tag, slices, arrays and maps are formatted as json.
+func formatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() {
+		switch m := v.Addr().Interface().(type) {
+		case encoding.TextMarshaler:
+			text, err := m.MarshalText()
+			if err != nil {
+				return ""
+			}
+			return string(text)
+		case fmt.Stringer:
+			// like *time.Location
+			return m.String()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil() {
+			return ""
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
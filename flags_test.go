@@ -0,0 +1,130 @@
+package dl
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type flagsConfig struct {
+	Name    string            `default:"app" usage:"name of the service"`
+	Debug   bool              `default:"true"`
+	Workers *int              `default:"4"`
+	Tags    []string          `default:"[\"a\"]"`
+	Labels  map[string]string `default:"{\"env\": \"dev\"}"`
+	Start   time.Time         `default:"2024-01-01T00:00:00Z"`
+	Server  layeredServer
+	Backup  *layeredServer
+	Any     any
+	Skip    string `default:"-"`
+}
+
+func TestBindFlags(t *testing.T) {
+	c := &flagsConfig{}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	if err := BindFlags(fs, c, "app"); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	expected := []string{
+		"app.backup.host", "app.backup.port", "app.backup.read-timeout",
+		"app.debug", "app.labels", "app.name",
+		"app.server.host", "app.server.port", "app.server.read-timeout",
+		"app.start", "app.tags", "app.workers",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("it should register a flag for each leaf field\ngot  %v\nwant %v", names, expected)
+	}
+
+	defaults := map[string]string{
+		"app.name":                "app",
+		"app.debug":               "true",
+		"app.workers":             "4",
+		"app.tags":                `["a"]`,
+		"app.labels":              `{"env":"dev"}`,
+		"app.start":               "2024-01-01T00:00:00Z",
+		"app.server.read-timeout": "5s",
+		"app.backup.host":         "",
+	}
+	for name, def := range defaults {
+		if f := fs.Lookup(name); f.DefValue != def {
+			t.Errorf("it should use the default of %s, got %q expected %q", name, f.DefValue, def)
+		}
+	}
+	if fs.Lookup("app.name").Usage != "name of the service" || fs.Lookup("app.server.port").Usage != "Server.Port" {
+		t.Errorf("it should use the usage tag or the path as the usage")
+	}
+
+	err := fs.Parse([]string{
+		"-app.name=set",
+		"-app.debug=false",
+		"-app.workers=8",
+		"-app.tags", `["b", "c"]`,
+		"-app.server.read-timeout=1m",
+		"-app.backup.port=81",
+		"-app.start=2024-02-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("it should parse the flags: %v", err)
+	}
+	if c.Name != "set" || c.Debug || *c.Workers != 8 || strings.Join(c.Tags, ",") != "b,c" {
+		t.Errorf("it should write the flags into the struct, got %+v", c)
+	}
+	if c.Server.ReadTimeout != time.Minute || c.Server.Port != 80 {
+		t.Errorf("it should parse durations like tags, got %+v", c.Server)
+	}
+	if c.Backup == nil || c.Backup.Port != 81 || c.Backup.Host != "localhost" {
+		t.Errorf("it should allocate nil pointers with their defaults, got %+v", c.Backup)
+	}
+	if !c.Start.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("it should parse text unmarshalers, got %s", c.Start)
+	}
+
+	t.Run("bool", func(t *testing.T) {
+		c := &flagsConfig{}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		if err := BindFlags(fs, c, ""); err != nil {
+			t.Fatal(err)
+		}
+		c.Debug = false
+		if err := fs.Parse([]string{"-debug"}); err != nil || !c.Debug {
+			t.Errorf("it should allow bool flags without a value, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		var out bytes.Buffer
+		fs.SetOutput(&out)
+		if err := BindFlags(fs, &flagsConfig{}, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.Parse([]string{"-server.port=eighty"}); err == nil || !strings.Contains(err.Error(), "Server.Port") {
+			t.Errorf("it should report values which cannot be parsed, got %v", err)
+		}
+	})
+
+	t.Run("layered", func(t *testing.T) {
+		c := &flagsConfig{}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		if err := BindFlags(fs, c, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.Parse([]string{"-server.port=82"}); err != nil {
+			t.Fatal(err)
+		}
+		file := writeFile(t, "config.json", `{"Server": {"Port": 81}, "Name": "file"}`)
+		layered := NewLayered().File(file).Flags(fs, "")
+		if err := layered.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Server.Port != 82 || c.Name != "file" || layered.Source("Server.Port") != SourceFlag {
+			t.Errorf("it should apply bound flags over the file, got %+v", c)
+		}
+	})
+}
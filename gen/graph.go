@@ -5,6 +5,7 @@ package gen
 
 const (
 	defaultTagName  = "default"
+	unitTagName     = "unit"
 	defaultFuncName = "Default"
 )
 
@@ -27,12 +28,53 @@ func (s Struct) IsValid() bool {
 	return len(s.Fields) > 0
 }
 
+// HasProfiles checks if a field has profile values, or loads a nested struct which may have them.
+// The profile method of a struct without them calls the default method.
+func (s Struct) HasProfiles() bool {
+	for _, f := range s.Fields {
+		if len(f.Profiles) > 0 || !f.IsBasic {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileFuncName returns the name of the method loading the default values of a profile.
+func (s Struct) ProfileFuncName() string {
+	return s.DefaultFuncName + "Profile"
+}
+
 // Field represents a field in the struct.
 type Field struct {
 	IsBasic bool
-	Name    string
-	Type    string
-	Value   string
+	// IsEmbedded is set for embedded fields, Type is the embedded type without the pointer.
+	IsEmbedded bool
+	IsPointer  bool
+	// Alloc is set for tagged embedded pointers which are allocated when nil.
+	Alloc bool
+	// IsUnit is set when the quoted Value is parsed by dl.SetUnit when the Default method runs,
+	// by the unit tag or else by the unit registered for the type.
+	IsUnit bool
+	// Unit is the unit tag.
+	Unit  string
+	Name  string
+	Type  string
+	Value string
+	// Profiles are the values of the profile tags, like `default.prod`, Value is empty without a default tag.
+	Profiles []*Profile
+}
+
+// Profile is the value of a field in a profile.
+type Profile struct {
+	Name  string
+	Value string
+}
+
+// WithValue returns a copy of the field holding value, it is used to assign the value of a profile.
+func (f Field) WithValue(value string) *Field {
+	f.Value = value
+	f.Profiles = nil
+	return &f
 }
 
 // IsValid checks if the field is valid.
@@ -16,6 +16,23 @@ import (
 {{ end -}}
 )
 
+{{- define "assign" }}
+    {{- if .IsUnit }}
+    {{- if .IsPointer }}
+    obj.{{ .Name }} = new({{ .Type }})
+    if err := dl.SetUnit(obj.{{ .Name }}, {{ .Value }}, "{{ .Unit }}"); err != nil {
+        return err
+    }
+    {{- else }}
+    if err := dl.SetUnit(&obj.{{ .Name }}, {{ .Value }}, "{{ .Unit }}"); err != nil {
+        return err
+    }
+    {{- end }}
+    {{- else }}
+    obj.{{ .Name }} = {{ .Value }}
+    {{- end }}
+{{- end }}
+
 {{- define "structs"}}
 {{ range $s := $.Structs }}
 {{- if $s.IsValid }}
@@ -23,7 +40,24 @@ import (
 func (obj *{{ $s.Name }}) {{ $s.DefaultFuncName }}() error {
 {{- range $f := $s.Fields }}
     {{- if $f.IsBasic }}
-    obj.{{ $f.Name }} = {{ $f.Value }}
+    {{- if $f.Value }}
+    {{- template "assign" $f }}
+    {{- end }}
+    {{- else if $f.IsPointer }}
+    {{- if $f.Alloc }}
+    if obj.{{ $f.Name }} == nil {
+        obj.{{ $f.Name }} = new({{ $f.Type }})
+    }
+    if err := dl.Load(obj.{{ $f.Name }}); err != nil {
+        return err
+    }
+    {{- else }}
+    if obj.{{ $f.Name }} != nil {
+        if err := dl.Load(obj.{{ $f.Name }}); err != nil {
+            return err
+        }
+    }
+    {{- end }}
     {{- else }}
     if err := dl.Load(&obj.{{ $f.Name }}); err != nil {
         return err
@@ -32,6 +66,54 @@ func (obj *{{ $s.Name }}) {{ $s.DefaultFuncName }}() error {
 {{- end }}
     return nil
 }
+
+// {{ $s.ProfileFuncName }} loads default values of the profile for {{ $s.Name }},
+// fields without a value in the profile get their default values
+func (obj *{{ $s.Name }}) {{ $s.ProfileFuncName }}(profile string) error {
+{{- if not $s.HasProfiles }}
+    return obj.{{ $s.DefaultFuncName }}()
+}
+{{- else }}
+{{- range $f := $s.Fields }}
+    {{- if $f.IsBasic }}
+    {{- if $f.Profiles }}
+    switch profile {
+    {{- range $p := $f.Profiles }}
+    case "{{ $p.Name }}":
+        {{- template "assign" ($f.WithValue $p.Value) }}
+    {{- end }}
+    {{- if $f.Value }}
+    default:
+        {{- template "assign" $f }}
+    {{- end }}
+    }
+    {{- else if $f.Value }}
+    {{- template "assign" $f }}
+    {{- end }}
+    {{- else if $f.IsPointer }}
+    {{- if $f.Alloc }}
+    if obj.{{ $f.Name }} == nil {
+        obj.{{ $f.Name }} = new({{ $f.Type }})
+    }
+    if err := dl.Load(obj.{{ $f.Name }}, dl.WithProfile(profile)); err != nil {
+        return err
+    }
+    {{- else }}
+    if obj.{{ $f.Name }} != nil {
+        if err := dl.Load(obj.{{ $f.Name }}, dl.WithProfile(profile)); err != nil {
+            return err
+        }
+    }
+    {{- end }}
+    {{- else }}
+    if err := dl.Load(&obj.{{ $f.Name }}, dl.WithProfile(profile)); err != nil {
+        return err
+    }
+    {{- end }}
+{{- end }}
+    return nil
+}
+{{- end }}
 {{- end }}
 {{- end }}
 {{- end }}
\ No newline at end of file
@@ -9,6 +9,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"strconv"
 	"strings"
 )
 
@@ -32,6 +33,7 @@ func ParseFromFile(fileName string) (*Graph, error) {
 }
 
 func ParseFromAstFile(f *ast.File, graph *Graph) error {
+	types := typeDecls(f)
 	// range over the objects in the scope of this generated AST and check for StructType. Then range over fields
 	// contained in that struct.
 	ast.Inspect(f, func(n ast.Node) bool {
@@ -46,7 +48,7 @@ func ParseFromAstFile(f *ast.File, graph *Graph) error {
 					Name:            t.Name.Name,
 					DefaultFuncName: defaultFuncName,
 				}
-				parseStructTags(s, v)
+				parseStructTags(s, v, types)
 				if s.IsValid() {
 					graph.Structs = append(graph.Structs, s)
 				}
@@ -57,9 +59,26 @@ func ParseFromAstFile(f *ast.File, graph *Graph) error {
 	return nil
 }
 
-func parseFieldTag(field *ast.Field, tagName string) *Field {
+// typeDecls returns the types declared in f by their names.
+func typeDecls(f *ast.File) map[string]ast.Expr {
+	types := make(map[string]ast.Expr)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if t, ok := spec.(*ast.TypeSpec); ok {
+				types[t.Name.Name] = t.Type
+			}
+		}
+	}
+	return types
+}
+
+func parseFieldTag(field *ast.Field, tagName string, types map[string]ast.Expr) *Field {
 	if len(field.Names) == 0 {
-		return nil
+		return parseEmbeddedField(field, tagName, types)
 	}
 	fieldName := field.Names[0].String()
 
@@ -68,7 +87,7 @@ func parseFieldTag(field *ast.Field, tagName string) *Field {
 			Name:            field.Names[0].String(),
 			DefaultFuncName: defaultFuncName,
 		}
-		parseStructTags(sub, v)
+		parseStructTags(sub, v, types)
 
 		// TODO: now used reflect to set the unsupported type by `dl.Load`
 		return &Field{
@@ -83,18 +102,138 @@ func parseFieldTag(field *ast.Field, tagName string) *Field {
 
 	tags := StructTagFromString(field.Tag.Value)
 	val := tags.Get(tagName)
-	if !(validateTag(val)) {
+	profiles := parseProfiles(tags, tagName)
+	if val == "-" || (!validateTag(val) && len(profiles) == 0) {
 		return nil
 	}
 	fieldType := parseType(field.Type)
 	debugPrint("field tag:",
 		fmt.Sprintf("tagName: %s, fieldName: %s, fieldType: %s, tagVal: %s",
 			tagName, fieldName, fieldType, val))
+	f := &Field{
+		IsBasic:  true,
+		Unit:     tags.Get(unitTagName),
+		Name:     fieldName,
+		Type:     fieldType,
+		Value:    val,
+		Profiles: profiles,
+	}
+	f.IsUnit = f.Unit != "" || isUnitValue(types, field.Type, val)
+	for _, p := range profiles {
+		f.IsUnit = f.IsUnit || isUnitValue(types, field.Type, p.Value)
+	}
+	if star, ok := field.Type.(*ast.StarExpr); ok && f.IsUnit {
+		// the value is parsed into a newly allocated element
+		f.IsPointer = true
+		f.Type = parseType(star.X)
+	}
+	return f
+}
+
+// predeclaredTypes are the builtin types, whose values are not parsed by a registered unit.
+var predeclaredTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true, "error": true, "any": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
+
+// integerTypes are the builtin integer types.
+var integerTypes = map[string]bool{
+	"byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+// isUnitValue reports whether the value of a named integer type is parsed by the unit registered for the type,
+// like `64MiB` for `type ByteSize int64`: it starts with a digit and is not a Go number.
+// The type is time.Duration or declared in the file as an integer, other types are left to their literal value.
+func isUnitValue(types map[string]ast.Expr, typo ast.Expr, val string) bool {
+	if star, ok := typo.(*ast.StarExpr); ok {
+		typo = star.X
+	}
+	if v, ok := typo.(*ast.Ident); ok && predeclaredTypes[v.Name] {
+		return false
+	}
+	if !isIntegerType(types, typo) {
+		return false
+	}
+	if val == "" || val[0] < '0' || val[0] > '9' {
+		return false
+	}
+	if _, err := strconv.ParseInt(val, 0, 64); err == nil {
+		return false
+	}
+	_, err := strconv.ParseFloat(val, 64)
+	return err != nil
+}
+
+// isIntegerType reports whether typo resolves to an integer type by the types declared in the file,
+// time.Duration is the only integer type of another package known.
+func isIntegerType(types map[string]ast.Expr, typo ast.Expr) bool {
+	// a declaration chain is at most as long as the declarations, a longer one is a loop
+	for i := 0; i <= len(types); i++ {
+		switch v := typo.(type) {
+		case *ast.Ident:
+			if integerTypes[v.Name] {
+				return true
+			}
+			t, ok := types[v.Name]
+			if !ok {
+				return false
+			}
+			typo = t
+		case *ast.SelectorExpr:
+			pkg, ok := v.X.(*ast.Ident)
+			return ok && pkg.Name == "time" && v.Sel.Name == "Duration"
+		case *ast.ParenExpr:
+			typo = v.X
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// parseEmbeddedField returns the field of an embedded struct or pointer to struct.
+// Embedded types are loaded when they are tagged or declared as a struct in the same file,
+// embedded pointers are only allocated when they are tagged.
+func parseEmbeddedField(field *ast.Field, tagName string, types map[string]ast.Expr) *Field {
+	var val string
+	if field.Tag != nil {
+		val = StructTagFromString(field.Tag.Value).Get(tagName)
+	}
+	if val == "-" {
+		return nil
+	}
+
+	typo := field.Type
+	star, isPointer := typo.(*ast.StarExpr)
+	if isPointer {
+		typo = star.X
+	}
+	var name string
+	switch v := typo.(type) {
+	case *ast.Ident:
+		name = v.Name
+	case *ast.SelectorExpr:
+		name = v.Sel.Name
+	default:
+		return nil
+	}
+	if _, ok := types[parseType(typo)].(*ast.StructType); val == "" && !ok {
+		return nil
+	}
+
+	debugPrint("embedded field:",
+		fmt.Sprintf("tagName: %s, fieldName: %s, pointer: %t, tagVal: %s",
+			tagName, name, isPointer, val))
 	return &Field{
-		IsBasic: true,
-		Name:    fieldName,
-		Type:    fieldType,
-		Value:   val,
+		Name:       name,
+		Type:       parseType(typo),
+		IsEmbedded: true,
+		IsPointer:  isPointer,
+		Alloc:      isPointer && val != "",
 	}
 }
 
@@ -119,7 +258,7 @@ func parseType(x ast.Expr) string {
 	}
 }
 
-func parseStructTags(gs *Struct, x *ast.StructType) {
+func parseStructTags(gs *Struct, x *ast.StructType, types map[string]ast.Expr) {
 	for _, field := range x.Fields.List {
 		debugPrint("struct tags:", fmt.Sprintf("Type(%T)", field.Type), fmt.Sprintf("Value(%+v) ", field))
 		// switch field.Type.(type) {
@@ -136,7 +275,7 @@ func parseStructTags(gs *Struct, x *ast.StructType) {
 		// 	})
 		// }
 
-		tagValue := parseFieldTag(field, defaultTagName)
+		tagValue := parseFieldTag(field, defaultTagName, types)
 		if tagValue != nil {
 			gs.Fields = append(gs.Fields, formatField(tagValue))
 		}
@@ -144,10 +283,34 @@ func parseStructTags(gs *Struct, x *ast.StructType) {
 }
 
 func formatField(value *Field) *Field {
-	value.Value = formatValue(value.Type, value.Value)
+	format := func(v string) string {
+		switch {
+		case v == "":
+			return ""
+		case value.IsUnit:
+			return strconv.Quote(v)
+		default:
+			return formatValue(value.Type, v)
+		}
+	}
+	value.Value = format(value.Value)
+	for _, p := range value.Profiles {
+		p.Value = format(p.Value)
+	}
 	return value
 }
 
+// parseProfiles returns the values of the profile tags, like `default.prod` for the profile prod, in tag order.
+func parseProfiles(tags StructTag, tagName string) []*Profile {
+	var profiles []*Profile
+	for _, key := range tagKeys(tags) {
+		if name := strings.TrimPrefix(key, tagName+"."); name != key && name != "" {
+			profiles = append(profiles, &Profile{Name: name, Value: tags.Get(key)})
+		}
+	}
+	return profiles
+}
+
 func formatValue(typo string, value string) string {
 	switch {
 	case strings.HasPrefix(typo, "*"):
@@ -1,6 +1,9 @@
 package gen
 
 import (
+	"go/parser"
+	"go/token"
+	"reflect"
 	"testing"
 )
 
@@ -47,3 +50,169 @@ func TestMapKeyIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestParseEmbeddedFields(t *testing.T) {
+	src := `package example
+
+import "net/http"
+
+type Inner struct {
+	Name string ` + "`default:\"inner\"`" + `
+}
+
+type inner struct {
+	Port int ` + "`default:\"80\"`" + `
+}
+
+type Outer struct {
+	Inner
+	*inner ` + "`default:\"{}\"`" + `
+	*http.Client ` + "`default:\"{}\"`" + `
+	http.Header
+	Ignored ` + "`default:\"-\"`" + `
+	Value string ` + "`default:\"outer\"`" + `
+}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "outer.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var graph Graph
+	if err := ParseFromAstFile(f, &graph); err != nil {
+		t.Fatal(err)
+	}
+
+	var outer *Struct
+	for _, s := range graph.Structs {
+		if s.Name == "Outer" {
+			outer = s
+		}
+	}
+	if outer == nil {
+		t.Fatal("Expected struct Outer to be parsed")
+	}
+
+	expected := []Field{
+		{IsEmbedded: true, Name: "Inner", Type: "Inner"},
+		{IsEmbedded: true, IsPointer: true, Alloc: true, Name: "inner", Type: "inner"},
+		{IsEmbedded: true, IsPointer: true, Alloc: true, Name: "Client", Type: "http.Client"},
+		{IsBasic: true, Name: "Value", Type: "string", Value: `"outer"`},
+	}
+	if len(outer.Fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d: %+v", len(expected), len(outer.Fields), outer.Fields)
+	}
+	for i, f := range outer.Fields {
+		if !reflect.DeepEqual(*f, expected[i]) {
+			t.Errorf("Expected field %+v, got %+v", expected[i], *f)
+		}
+	}
+}
+
+func TestParseUnitFields(t *testing.T) {
+	src := `package example
+
+type ByteSize int64
+
+type Version string
+
+type Limits struct {
+	Buffer    int ` + "`default:\"64MiB\" unit:\"bytes\"`" + `
+	Retention *time.Duration ` + "`default:\"7d\" unit:\"duration\"`" + `
+	Count     int ` + "`default:\"10\"`" + `
+	Size      ByteSize ` + "`default:\"1GiB\"`" + `
+	Timeout   time.Duration ` + "`default:\"5s\"`" + `
+	Limit     ByteSize ` + "`default:\"1024\"`" + `
+	Level     Level ` + "`default:\"Info\"`" + `
+	Version   Version ` + "`default:\"1.2.3\"`" + `
+	Other     other.Size ` + "`default:\"1GiB\"`" + `
+}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "limits.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var graph Graph
+	if err := ParseFromAstFile(f, &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Structs) != 1 {
+		t.Fatalf("Expected struct Limits to be parsed, got %+v", graph.Structs)
+	}
+
+	expected := []Field{
+		{IsBasic: true, IsUnit: true, Unit: "bytes", Name: "Buffer", Type: "int", Value: `"64MiB"`},
+		{IsBasic: true, IsPointer: true, IsUnit: true, Unit: "duration", Name: "Retention", Type: "time.Duration", Value: `"7d"`},
+		{IsBasic: true, Name: "Count", Type: "int", Value: "10"},
+		{IsBasic: true, IsUnit: true, Name: "Size", Type: "ByteSize", Value: `"1GiB"`},
+		{IsBasic: true, IsUnit: true, Name: "Timeout", Type: "time.Duration", Value: `"5s"`},
+		{IsBasic: true, Name: "Limit", Type: "ByteSize", Value: "1024"},
+		{IsBasic: true, Name: "Level", Type: "Level", Value: "Info"},
+		{IsBasic: true, Name: "Version", Type: "Version", Value: "1.2.3"},
+		{IsBasic: true, Name: "Other", Type: "other.Size", Value: "1GiB"},
+	}
+	fields := graph.Structs[0].Fields
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d: %+v", len(expected), len(fields), fields)
+	}
+	for i, f := range fields {
+		if !reflect.DeepEqual(*f, expected[i]) {
+			t.Errorf("Expected field %+v, got %+v", expected[i], *f)
+		}
+	}
+}
+
+func TestParseProfileFields(t *testing.T) {
+	src := `package example
+
+type Log struct {
+	Level  string ` + "`default:\"info\" default.dev:\"debug\" default.prod:\"warn\"`" + `
+	Buffer int ` + "`default.prod:\"64MiB\" unit:\"bytes\"`" + `
+	Skip   string ` + "`default:\"-\" default.dev:\"debug\"`" + `
+}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "log.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var graph Graph
+	if err := ParseFromAstFile(f, &graph); err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Structs) != 1 {
+		t.Fatalf("Expected struct Log to be parsed, got %+v", graph.Structs)
+	}
+
+	expected := []Field{
+		{IsBasic: true, Name: "Level", Type: "string", Value: `"info"`, Profiles: []*Profile{
+			{Name: "dev", Value: `"debug"`},
+			{Name: "prod", Value: `"warn"`},
+		}},
+		{IsBasic: true, IsUnit: true, Unit: "bytes", Name: "Buffer", Type: "int", Profiles: []*Profile{
+			{Name: "prod", Value: `"64MiB"`},
+		}},
+	}
+	fields := graph.Structs[0].Fields
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d fields, got %d: %+v", len(expected), len(fields), fields)
+	}
+	for i, f := range fields {
+		if !reflect.DeepEqual(*f, expected[i]) {
+			t.Errorf("Expected field %+v, got %+v", expected[i], *f)
+		}
+	}
+}
+
+func TestStructHasProfiles(t *testing.T) {
+	s := Struct{Fields: []*Field{{IsBasic: true, Name: "Name", Value: `"app"`}}}
+	if s.HasProfiles() {
+		t.Errorf("Expected a struct without profile values to have no profiles")
+	}
+	s.Fields = append(s.Fields, &Field{Name: "Server", Type: "Server"})
+	if !s.HasProfiles() {
+		t.Errorf("Expected a struct loading a nested struct to pass the profile on")
+	}
+	s.Fields = []*Field{{IsBasic: true, Name: "Level", Profiles: []*Profile{{Name: "prod", Value: `"warn"`}}}}
+	if !s.HasProfiles() {
+		t.Errorf("Expected a struct with profile values to have profiles")
+	}
+}
@@ -9,6 +9,43 @@ import (
 
 type StructTag = reflect.StructTag
 
+// tagKeys returns the keys of tag in order, it follows the syntax of reflect.StructTag.Lookup.
+func tagKeys(tag StructTag) []string {
+	var keys []string
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		keys = append(keys, string(tag[:i]))
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		tag = tag[i+1:]
+	}
+	return keys
+}
+
 // StructTagFromString creates a new StructTag by trimming the backticks from the input string.
 func StructTagFromString(v string) StructTag {
 	return StructTag(trimSide(v, "`"))
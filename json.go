@@ -0,0 +1,351 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// UnmarshalJSON loads the defaults of the value referenced by `ptr` and then decodes data into it.
+// Unlike a Load after json.Unmarshal, slice elements, map values and pointers created by the decoding
+// get their defaults before they are decoded, so keys absent from data keep their defaults at every level
+// and explicit zero values are kept.
+// `ptr` should be a pointer
+func UnmarshalJSON[T any](data []byte, ptr *T, opts ...Option) error {
+	return defaultLoader.with(opts...).LoadJSON(data, ptr)
+}
+
+// LoadJSON loads the defaults of the value referenced by `ptr` and then decodes data into it,
+// values created by the decoding get their defaults before they are decoded.
+// `ptr` should be a pointer
+func (l *Loader) LoadJSON(data []byte, ptr any) error {
+	kind := reflect.TypeOf(ptr).Kind()
+	if kind != reflect.Ptr {
+		return InvalidTypeError(kind.String())
+	}
+	// report syntax errors before anything is loaded
+	if err := json.Unmarshal(data, new(json.RawMessage)); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(ptr).Elem()
+	if v.Kind() == reflect.Struct {
+		if err := l.Load(ptr); err != nil {
+			return err
+		}
+	}
+	return l.decodeJSON(v, data, nil)
+}
+
+// decodeJSON decodes data into v without loading the defaults of v itself,
+// decoded is called with the path of every decoded field when it is set.
+func (l *Loader) decodeJSON(v reflect.Value, data []byte, decoded func(path string)) error {
+	// report syntax errors before anything is decoded
+	if err := json.Unmarshal(data, new(json.RawMessage)); err != nil {
+		return err
+	}
+	d := &jsonDecoder{l: l, decoded: decoded}
+	return d.decode(v, data)
+}
+
+// jsonField is a field decoded from the object key name.
+type jsonField struct {
+	name  string
+	index []int
+	// path holds the names of the field and the exported embedded structs it is promoted from
+	path []string
+	// quoted is set by the string option on scalar fields, the value is encoded in a json string
+	quoted bool
+}
+
+// jsonFields caches the []jsonField of each struct type.
+var jsonFields sync.Map
+
+// jsonFieldsOf returns the fields of t decoded by encoding/json, with the fields of embedded structs promoted.
+func jsonFieldsOf(t reflect.Type) []jsonField {
+	if f, ok := jsonFields.Load(t); ok {
+		return f.([]jsonField)
+	}
+
+	type candidate struct {
+		jsonField
+		tagged bool
+	}
+	var candidates []candidate
+	var walk func(t reflect.Type, index []int, path []string)
+	walk = func(t reflect.Type, index []int, path []string) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			idx := append(append([]int(nil), index...), i)
+			p := append(append([]string(nil), path...), sf.Name)
+			if sf.Anonymous && name == "" && isStruct(sf.Type) {
+				if !sf.IsExported() {
+					// promoted fields keep their own names
+					p = p[:len(p)-1]
+				}
+				walk(indirect(sf.Type), idx, p)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			c := candidate{
+				jsonField: jsonField{name: name, index: idx, path: p, quoted: hasJSONOption(opts, "string") && isJSONQuotable(sf.Type)},
+				tagged:    name != "",
+			}
+			if name == "" {
+				c.name = sf.Name
+			}
+			candidates = append(candidates, c)
+		}
+	}
+	walk(t, nil, nil)
+
+	// a name held by several fields belongs to the shallowest one, then to the tagged one, or else to none
+	fields := make([]jsonField, 0, len(candidates))
+	for i, c := range candidates {
+		dominant := true
+		for j, o := range candidates {
+			if i == j || o.name != c.name {
+				continue
+			}
+			if len(o.index) < len(c.index) || (len(o.index) == len(c.index) && (o.tagged == c.tagged || o.tagged)) {
+				dominant = false
+				break
+			}
+		}
+		if dominant {
+			fields = append(fields, c.jsonField)
+		}
+	}
+
+	f, _ := jsonFields.LoadOrStore(t, fields)
+	return f.([]jsonField)
+}
+
+// isJSONQuotable reports whether the string option applies to fields of type t,
+// encoding/json ignores it on other kinds than strings, booleans and numbers.
+func isJSONQuotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasJSONOption reports whether the comma separated options of a json tag hold option.
+func hasJSONOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonDecoder decodes json like encoding/json, new structs get their defaults before they are decoded.
+type jsonDecoder struct {
+	l *Loader
+	// decoded is called with the path of every decoded field which is not a nested struct, when it is set
+	decoded func(path string)
+	path    fieldPath
+}
+
+// decode decodes raw into v.
+func (d *jsonDecoder) decode(v reflect.Value, raw []byte) error {
+	raw = bytes.TrimSpace(raw)
+	if string(raw) == "null" {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			v.Set(reflect.Zero(v.Type()))
+		default:
+			// nothing to do
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+			if err := d.loadNew(v.Elem()); err != nil {
+				return err
+			}
+		}
+		return d.decode(v.Elem(), raw)
+	}
+	if implementsUnmarshaler(v.Type()) {
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+
+	// values of other json types are left to encoding/json, which reports the type errors
+	switch {
+	case v.Kind() == reflect.Struct && raw[0] == '{':
+		return d.decodeJSONObject(v, raw)
+	case v.Kind() == reflect.Slice && raw[0] == '[':
+		return d.decodeJSONSlice(v, raw)
+	case v.Kind() == reflect.Array && raw[0] == '[':
+		return d.decodeJSONArray(v, raw)
+	case v.Kind() == reflect.Map && raw[0] == '{':
+		return d.decodeJSONMap(v, raw)
+	default:
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+}
+
+func (d *jsonDecoder) decodeJSONObject(v reflect.Value, raw []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	fields := jsonFieldsOf(v.Type())
+	for key, value := range values {
+		f := findJSONField(fields, key)
+		if f == nil {
+			continue
+		}
+		field, err := d.l.fieldByIndex(v, f.index, true)
+		if err != nil {
+			return err
+		}
+		if f.quoted && string(value) != "null" {
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return err
+			}
+			value = json.RawMessage(s)
+		}
+		if err := d.decodeField(field, f.path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeField decodes the value of a struct field, and reports its path unless it is a nested struct.
+func (d *jsonDecoder) decodeField(field reflect.Value, path []string, value json.RawMessage) error {
+	if d.decoded == nil {
+		return d.decode(field, value)
+	}
+	for _, name := range path {
+		d.path = d.path.push(name)
+	}
+	defer func() { d.path = d.path[:len(d.path)-len(path)] }()
+	if err := d.decode(field, value); err != nil {
+		return err
+	}
+	t := indirect(field.Type())
+	if t.Kind() != reflect.Struct || implementsUnmarshaler(t) || bytes.TrimSpace(value)[0] != '{' {
+		d.decoded(d.path.String())
+	}
+	return nil
+}
+
+// findJSONField returns the field named key, or else the first one whose name matches key case-insensitively.
+func findJSONField(fields []jsonField, key string) *jsonField {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func (d *jsonDecoder) decodeJSONSlice(v reflect.Value, raw []byte) error {
+	defer d.untracked()()
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	s := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := d.loadNew(s.Index(i)); err != nil {
+			return err
+		}
+		if err := d.decode(s.Index(i), value); err != nil {
+			return err
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+// decodeJSONArray decodes into the elements of the array, the elements beyond the values are set to zero.
+func (d *jsonDecoder) decodeJSONArray(v reflect.Value, raw []byte) error {
+	defer d.untracked()()
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if i >= len(values) {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			continue
+		}
+		if err := d.decode(v.Index(i), values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeJSONMap decodes the values into the map, the keys absent from raw are kept.
+func (d *jsonDecoder) decodeJSONMap(v reflect.Value, raw []byte) error {
+	defer d.untracked()()
+	values := reflect.New(reflect.MapOf(v.Type().Key(), rawMessageType))
+	if err := json.Unmarshal(raw, values.Interface()); err != nil {
+		return err
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), values.Elem().Len()))
+	}
+	iter := values.Elem().MapRange()
+	for iter.Next() {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.loadNew(elem); err != nil {
+			return err
+		}
+		if err := d.decode(elem, iter.Value().Interface().(json.RawMessage)); err != nil {
+			return err
+		}
+		v.SetMapIndex(iter.Key(), elem)
+	}
+	return nil
+}
+
+// untracked stops reporting paths inside elements, which are reported with their slice, array or map.
+// It returns the function restoring the reporting.
+func (d *jsonDecoder) untracked() func() {
+	decoded := d.decoded
+	d.decoded = nil
+	return func() { d.decoded = decoded }
+}
+
+// loadNew loads the defaults of a struct created by the decoding.
+func (d *jsonDecoder) loadNew(v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	return d.l.Load(v.Addr().Interface())
+}
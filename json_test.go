@@ -0,0 +1,127 @@
+package dl
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type jsonTLS struct {
+	Enabled bool   `json:"enabled" default:"true"`
+	Cert    string `json:"cert" default:"cert.pem"`
+}
+
+type jsonServer struct {
+	Host    string        `json:"host" default:"localhost"`
+	Port    int           `json:"port" default:"80"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	TLS     *jsonTLS      `json:"tls"`
+}
+
+type jsonLimits struct {
+	Burst int `default:"10"`
+}
+
+type jsonConfig struct {
+	*jsonLimits
+	Name    string                 `json:"name" default:"app"`
+	Debug   bool                   `json:"debug" default:"true"`
+	Retries int                    `json:"retries,omitempty,string" default:"3"`
+	Servers []jsonServer           `json:"servers" default:"[{\"host\": \"default\"}]"`
+	Peers   []*jsonServer          `json:"peers"`
+	Zones   map[string]*jsonServer `json:"zones" default:"{\"eu\": {}}"`
+	Pair    [2]jsonServer          `json:"pair"`
+	Main    jsonServer             `json:"main"`
+	Extra   any                    `json:"extra"`
+	Ignored string                 `json:"-" default:"ignored"`
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+		"Name": "set",
+		"debug": false,
+		"retries": "5",
+		"Burst": 20,
+		"servers": [{"host": "a"}, {"port": 8080, "tls": {"cert": "a.pem"}}],
+		"peers": [{"host": "p"}, null],
+		"zones": {"us": {"host": "us"}},
+		"pair": [{"host": "first"}],
+		"main": {"tls": {}},
+		"extra": {"key": 1},
+		"ignored": "set",
+		"unknown": true
+	}`)
+	c := &jsonConfig{}
+	if err := UnmarshalJSON(data, c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Name != "set" || c.Debug || c.Retries != 5 {
+		t.Errorf("it should decode over the defaults and keep explicit zero values, got %+v", c)
+	}
+	if c.jsonLimits == nil || c.Burst != 20 {
+		t.Errorf("it should decode promoted fields of embedded pointers, got %+v", c.jsonLimits)
+	}
+	if len(c.Servers) != 2 || c.Servers[0].Host != "a" || c.Servers[0].Port != 80 || c.Servers[0].Timeout != 5*time.Second {
+		t.Errorf("it should load the defaults of new slice elements, got %+v", c.Servers)
+	}
+	if s := c.Servers[1]; s.Host != "localhost" || s.Port != 8080 || s.TLS == nil || !s.TLS.Enabled || s.TLS.Cert != "a.pem" {
+		t.Errorf("it should load the defaults of new pointers, got %+v", s)
+	}
+	if len(c.Peers) != 2 || c.Peers[0].Port != 80 || c.Peers[1] != nil {
+		t.Errorf("it should load the defaults of pointer elements, got %+v", c.Peers)
+	}
+	if len(c.Zones) != 2 || c.Zones["eu"] == nil || c.Zones["us"].Host != "us" || c.Zones["us"].Port != 80 {
+		t.Errorf("it should keep default keys and load the defaults of new map values, got %+v", c.Zones)
+	}
+	if c.Pair[0].Host != "first" || c.Pair[0].Port != 80 || c.Pair[1] != (jsonServer{}) {
+		t.Errorf("it should decode arrays like encoding/json, got %+v", c.Pair)
+	}
+	if c.Main.Host != "localhost" || c.Main.TLS == nil || c.Main.TLS.Cert != "cert.pem" {
+		t.Errorf("it should keep the defaults of nested structs, got %+v", c.Main)
+	}
+	if m, ok := c.Extra.(map[string]any); !ok || m["key"] != float64(1) {
+		t.Errorf("it should decode interfaces like encoding/json, got %#v", c.Extra)
+	}
+	if c.Ignored != "ignored" {
+		t.Errorf("it should skip ignored fields, got %s", c.Ignored)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		var syntax *json.SyntaxError
+		if err := UnmarshalJSON([]byte(`{"name": `), &jsonConfig{}); !errors.As(err, &syntax) {
+			t.Errorf("it should return syntax errors, got %v", err)
+		}
+		var typ *json.UnmarshalTypeError
+		if err := UnmarshalJSON([]byte(`{"servers": {}}`), &jsonConfig{}); !errors.As(err, &typ) {
+			t.Errorf("it should return type errors, got %v", err)
+		}
+		quoted := &struct {
+			In  jsonServer        `json:",string"`
+			Out []int             `json:",string"`
+			Map map[string]string `json:",string"`
+		}{}
+		for _, data := range []string{`{"In":""}`, `{"Out":""}`, `{"Map":""}`} {
+			if err := NewLoader().LoadJSON([]byte(data), quoted); !errors.As(err, &typ) {
+				t.Errorf("it should ignore the string option of %s like encoding/json, got %v", data, err)
+			}
+		}
+		err := UnmarshalJSON([]byte(`{}`), &struct {
+			Port int `default:"80a"`
+		}{}, WithStrict(true))
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("it should return the errors of the defaults, got %v", err)
+		}
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		var servers []jsonServer
+		if err := UnmarshalJSON([]byte(`[{"host": "a"}]`), &servers); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if len(servers) != 1 || servers[0].Port != 80 {
+			t.Errorf("it should decode into non-struct values, got %+v", servers)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Source is the layer which set the value of a field.
+type Source string
+
+const (
+	// SourceDefault is the default tag or the DefaultLoader of the field.
+	SourceDefault Source = "default"
+	// SourceFile is a JSON file.
+	SourceFile Source = "file"
+	// SourceEnv is an environment variable or a .env file.
+	SourceEnv Source = "env"
+	// SourceFlag is a command-line flag.
+	SourceFlag Source = "flag"
+)
+
+// Layered loads a struct from layers applied in a fixed order, every layer overrides the previous ones:
+// the defaults, the JSON files, the environment variables and .env files, and the command-line flags.
+// It records the Source of each field set by a layer.
+type Layered struct {
+	l          *Loader
+	files      []string
+	dotEnvs    []string
+	env        bool
+	envPrefix  string
+	flags      *flag.FlagSet
+	flagPrefix string
+	sources    map[string]Source
+}
+
+// NewLayered creates a Layered which loads the defaults with opts.
+func NewLayered(opts ...Option) *Layered {
+	return &Layered{l: defaultLoader.with(opts...)}
+}
+
+// File adds a JSON file decoded by UnmarshalJSON, files are applied in the order they are added.
+func (b *Layered) File(path string) *Layered {
+	b.files = append(b.files, path)
+	return b
+}
+
+// DotEnv adds a .env file of `KEY=VALUE` lines to the environment layer,
+// variables set in the environment take precedence over the file, and earlier files over later ones.
+func (b *Layered) DotEnv(path string) *Layered {
+	b.dotEnvs = append(b.dotEnvs, path)
+	b.env = true
+	return b
+}
+
+// Env enables the environment layer, which sets fields with an env tag from their variable.
+// With a prefix, the other leaf fields are set from the variable named by the prefix and their path,
+// like `APP_SERVER_READ_TIMEOUT` for `Server.ReadTimeout` with the prefix `APP`.
+func (b *Layered) Env(prefix string) *Layered {
+	b.env = true
+	b.envPrefix = prefix
+	return b
+}
+
+// Flags adds the flags set in the parsed fs, a flag sets the leaf field named by the prefix and its kebab cased path,
+// like `app.server.read-timeout` for `Server.ReadTimeout` with the prefix `app`.
+func (b *Layered) Flags(fs *flag.FlagSet, prefix string) *Layered {
+	b.flags = fs
+	b.flagPrefix = prefix
+	return b
+}
+
+// Load applies the layers to the struct referenced by `ptr`.
+// `ptr` should be a struct pointer
+func (b *Layered) Load(ptr any) error {
+	v, err := structOf(ptr)
+	if err != nil {
+		return err
+	}
+	b.sources = make(map[string]Source)
+	leaves := b.l.leavesOf(v.Type())
+
+	if err := b.l.Load(ptr); err != nil {
+		return err
+	}
+	for _, leaf := range leaves {
+		field, err := b.l.fieldByIndex(v, leaf.index, false)
+		if err != nil {
+			return err
+		}
+		if field.IsValid() && !field.IsZero() {
+			b.sources[leaf.path.String()] = SourceDefault
+		}
+	}
+
+	for _, path := range b.files {
+		if err := b.loadFile(v, path); err != nil {
+			return err
+		}
+	}
+	if b.env {
+		if err := b.loadEnv(v, leaves); err != nil {
+			return err
+		}
+	}
+	if b.flags != nil {
+		return b.loadFlags(v, leaves)
+	}
+	return nil
+}
+
+// Source returns the layer which set the field at the dotted path, like `Server.Timeout`,
+// or an empty Source if no layer set it.
+func (b *Layered) Source(path string) Source {
+	return b.sources[path]
+}
+
+// Sources returns the layer which set each field by its dotted path, fields no layer set are absent.
+// Slices and maps are recorded as a whole.
+func (b *Layered) Sources() map[string]Source {
+	sources := make(map[string]Source, len(b.sources))
+	for path, s := range b.sources {
+		sources[path] = s
+	}
+	return sources
+}
+
+func (b *Layered) loadFile(v reflect.Value, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := b.l.decodeJSON(v, data, func(p string) { b.sources[p] = SourceFile }); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (b *Layered) loadEnv(v reflect.Value, leaves []leafField) error {
+	dotEnv := make(map[string]string)
+	for i := len(b.dotEnvs) - 1; i >= 0; i-- {
+		env, err := readDotEnv(b.dotEnvs[i])
+		if err != nil {
+			return err
+		}
+		for k, val := range env {
+			dotEnv[k] = val
+		}
+	}
+	lookup := func(name string) (string, bool) {
+		if val, ok := b.l.lookupEnv(name); ok {
+			return val, true
+		}
+		val, ok := dotEnv[name]
+		return val, ok
+	}
+
+	l := b.l.stringLoader()
+	for _, leaf := range leaves {
+		name := leaf.field.Tag.Get(envTagName)
+		if name == "" && b.envPrefix != "" {
+			name = envName(b.envPrefix, leaf.path)
+		}
+		if name == "" {
+			continue
+		}
+		val, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := b.setLeaf(l, v, leaf, val, SourceEnv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Layered) loadFlags(v reflect.Value, leaves []leafField) error {
+	byName := make(map[string]*leafField, len(leaves))
+	for i := range leaves {
+		byName[flagName(b.flagPrefix, leaves[i].path)] = &leaves[i]
+	}
+
+	l := b.l.stringLoader()
+	var err error
+	b.flags.Visit(func(f *flag.Flag) {
+		leaf, ok := byName[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		err = b.setLeaf(l, v, *leaf, f.Value.String(), SourceFlag)
+	})
+	return err
+}
+
+// setLeaf sets the leaf field of v from s and records its source.
+func (b *Layered) setLeaf(l *Loader, v reflect.Value, leaf leafField, s string, source Source) error {
+	field, err := l.fieldByIndex(v, leaf.index, true)
+	if err != nil {
+		return err
+	}
+	if err := l.setString(field, &leaf, s); err != nil {
+		return err
+	}
+	b.sources[leaf.path.String()] = source
+	return nil
+}
+
+// readDotEnv reads the `KEY=VALUE` lines of a .env file, blank lines and lines starting with # are skipped,
+// and values may be quoted.
+func readDotEnv(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: missing = in %q", path, i+1, line)
+		}
+		val = strings.TrimSpace(val)
+		if n := len(val); n >= 2 && (val[0] == '"' || val[0] == '\'') && val[n-1] == val[0] {
+			if unquoted, err := strconv.Unquote(val); err == nil && val[0] == '"' {
+				val = unquoted
+			} else {
+				val = val[1 : n-1]
+			}
+		}
+		env[strings.TrimSpace(key)] = val
+	}
+	return env, nil
+}
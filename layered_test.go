@@ -0,0 +1,136 @@
+package dl
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type layeredServer struct {
+	Host        string        `json:"host" default:"localhost"`
+	Port        int           `json:"port" default:"80"`
+	ReadTimeout time.Duration `json:"read_timeout" default:"5s"`
+}
+
+type layeredConfig struct {
+	Name   string            `json:"name" default:"app"`
+	Debug  bool              `json:"debug"`
+	Token  string            `json:"token" env:"APP_TOKEN"`
+	Tags   []string          `json:"tags" default:"[\"a\"]"`
+	Labels map[string]string `json:"labels"`
+	Server layeredServer     `json:"server"`
+	Backup *layeredServer    `json:"backup"`
+}
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLayered(t *testing.T) {
+	file := writeFile(t, "config.json", `{"name": "file", "debug": true, "tags": ["b"], "server": {"host": "file", "port": 81}}`)
+	dotEnv := writeFile(t, ".env", `
+# comment
+APP_TOKEN="secret\n"
+export APP_SERVER_PORT=82
+APP_SERVER_HOST='dotenv'
+`)
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("app.server.read-timeout", "", "")
+	fs.String("app.backup.host", "", "")
+	fs.String("app.name", "", "")
+	fs.String("other", "", "")
+	if err := fs.Parse([]string{"-app.server.read-timeout=1m", "-app.backup.host=flag", "-other=x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	layered := NewLayered(WithLookupEnv(lookupMap(map[string]string{"APP_SERVER_HOST": "env"}))).
+		File(file).
+		DotEnv(dotEnv).
+		Env("APP").
+		Flags(fs, "app")
+	c := &layeredConfig{}
+	if err := layered.Load(c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+
+	expected := &layeredConfig{
+		Name:   "file",
+		Debug:  true,
+		Token:  "secret\n",
+		Tags:   []string{"b"},
+		Server: layeredServer{Host: "env", Port: 82, ReadTimeout: time.Minute},
+		Backup: &layeredServer{Host: "flag", Port: 80, ReadTimeout: 5 * time.Second},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("it should apply the layers in order\ngot  %+v\nwant %+v", c, expected)
+	}
+
+	sources := map[string]Source{
+		"Name":               SourceFile,
+		"Debug":              SourceFile,
+		"Token":              SourceEnv,
+		"Tags":               SourceFile,
+		"Server.Host":        SourceEnv,
+		"Server.Port":        SourceEnv,
+		"Server.ReadTimeout": SourceFlag,
+		"Backup.Host":        SourceFlag,
+	}
+	if !reflect.DeepEqual(layered.Sources(), sources) {
+		t.Errorf("it should record the source of each field\ngot  %v\nwant %v", layered.Sources(), sources)
+	}
+	if layered.Source("Labels") != "" {
+		t.Errorf("it should not record fields no layer set")
+	}
+
+	c = &layeredConfig{}
+	layered = NewLayered()
+	if err := layered.Load(c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if layered.Source("Server.Port") != SourceDefault || layered.Source("Debug") != "" {
+		t.Errorf("it should record the defaults, got %v", layered.Sources())
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		if err := NewLayered().File(filepath.Join(t.TempDir(), "missing.json")).Load(&layeredConfig{}); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("it should return the error reading a file, got %v", err)
+		}
+		bad := writeFile(t, ".env", "APP_TOKEN")
+		if err := NewLayered().DotEnv(bad).Load(&layeredConfig{}); err == nil {
+			t.Errorf("it should return an error for invalid .env lines")
+		}
+		env := WithLookupEnv(lookupMap(map[string]string{"APP_SERVER_PORT": "eighty"}))
+		var fe *FieldError
+		if err := NewLayered(env).Env("APP").Load(&layeredConfig{}); !errors.As(err, &fe) || fe.Path != "Server.Port" {
+			t.Errorf("it should report values which cannot be parsed, got %v", err)
+		}
+	})
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := map[string]string{
+		"Name":        "name",
+		"ReadTimeout": "read-timeout",
+		"HTTPServer":  "http-server",
+		"TLS":         "tls",
+		"ID2":         "id2",
+		"Port2Host":   "port2-host",
+	}
+	for in, out := range tests {
+		if name := flagName("", fieldPath{{name: in}}); name != out {
+			t.Errorf("flagName(%s) returns %s, expected %s", in, name, out)
+		}
+	}
+	if name := envName("APP", fieldPath{{name: "Server"}, {name: "ReadTimeout"}}); name != "APP_SERVER_READ_TIMEOUT" {
+		t.Errorf("it should join the path with underscores, got %s", name)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// leafField is a field whose value is set from a single string, like an environment variable or a flag.
+type leafField struct {
+	// path holds the names of the field and the structs it is nested in, unexported embedded structs are skipped
+	path  fieldPath
+	index []int
+	field reflect.StructField
+	// def holds the companion tags of the field, like the layout
+	def *fieldDefault
+}
+
+// leavesOf returns the leaf fields of the struct type t, nested structs and pointers to structs are walked
+// unless they are parsed from a single string themselves, like time.Time.
+func (l *Loader) leavesOf(t reflect.Type) []leafField {
+	var leaves []leafField
+	var stack []reflect.Type
+	var walk func(t reflect.Type, path fieldPath, index []int)
+	walk = func(t reflect.Type, path fieldPath, index []int) {
+		for _, typ := range stack {
+			if typ == t {
+				return
+			}
+		}
+		stack = append(stack, t)
+		defer func() { stack = stack[:len(stack)-1] }()
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			hidden := sf.Anonymous && !sf.IsExported() && isStruct(sf.Type)
+			if (!sf.IsExported() && !hidden) || l.tagOf(sf) == "-" {
+				continue
+			}
+			idx := append(append([]int(nil), index...), i)
+			p := path
+			if !hidden {
+				p = append(append(fieldPath(nil), path...), pathSegment{name: sf.Name})
+			}
+
+			ft := indirect(sf.Type)
+			switch {
+			case ft.Kind() == reflect.Struct && !implementsUnmarshaler(ft) && !l.hasParser(ft) && sf.Type != locationType:
+				walk(ft, p, idx)
+			case ft.Kind() == reflect.Interface || ft.Kind() == reflect.Func || ft.Kind() == reflect.Chan:
+				// not set from strings
+			default:
+				leaves = append(leaves, leafField{path: p, index: idx, field: sf, def: l.compileDefault(sf, "")})
+			}
+		}
+	}
+	walk(t, nil, nil)
+	return leaves
+}
+
+// fieldByIndex returns the field of the struct v at index.
+// Nil pointers to structs on the way are allocated with their defaults when alloc is set,
+// otherwise the returned value is invalid.
+func (l *Loader) fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, error) {
+	for i, x := range index {
+		v = v.Field(x)
+		if !v.CanSet() {
+			v = embeddedField(v)
+		}
+		if i == len(index)-1 {
+			break
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, nil
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+				if err := l.Load(v.Interface()); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+			v = v.Elem()
+		}
+	}
+	return v, nil
+}
+
+// setString sets the field of leaf from s, which is parsed like a default tag.
+func (l *Loader) setString(field reflect.Value, leaf *leafField, s string) error {
+	w := &walker{l: l, loadInterface: l.loadInterfaceNoArg, path: leaf.path}
+	return w.setField(field, leaf.def.withRaw(s))
+}
+
+// stringLoader returns the Loader used by setString, which replaces values and reports every parse error.
+func (l *Loader) stringLoader() *Loader {
+	return l.with(WithMode(Overwrite), WithStrict(true), WithAllErrors(false))
+}
+
+// splitWords splits a Go identifier into its words, like `HTTPServer` into `HTTP` and `Server`.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower)) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// joinPath joins the words of the field names of path, each name is joined by nameSep and each word by wordSep.
+func joinPath(path fieldPath, nameSep, wordSep string, transform func(string) string) string {
+	names := make([]string, 0, len(path))
+	for _, s := range path {
+		words := splitWords(s.name)
+		for i := range words {
+			words[i] = transform(words[i])
+		}
+		names = append(names, strings.Join(words, wordSep))
+	}
+	return strings.Join(names, nameSep)
+}
+
+// flagName returns the kebab cased flag name of path, like `server.read-timeout`.
+func flagName(prefix string, path fieldPath) string {
+	name := joinPath(path, ".", "-", strings.ToLower)
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// envName returns the environment variable name of path, like `APP_SERVER_READ_TIMEOUT`.
+func envName(prefix string, path fieldPath) string {
+	name := joinPath(path, "_", "_", strings.ToUpper)
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
@@ -8,6 +8,12 @@ type DefaultLoader interface {
 	Default() error
 }
 
+// DefaultProfileLoader is an interface that can be implemented by structs to customize the default of a profile,
+// it is used instead of DefaultLoader when a profile is set by WithProfile.
+type DefaultProfileLoader interface {
+	DefaultProfile(profile string) error
+}
+
 // DefaultLoaderFunc is a function type that defines a function to load default values into a struct referenced by a pointer.
 type DefaultLoaderFunc[T any] func(*T) error
 
@@ -19,21 +25,30 @@ type DefaultOptionLoader[P any] interface {
 // DefaultOptionLoaderFunc is a function type that defines a function to load default values into a struct with a parameter.
 type DefaultOptionLoaderFunc[T any, P any] func(*T, P) error
 
+// BeforeDefaultsHook is an interface that can be implemented by structs to prepare themselves
+// before their tag defaults are applied.
+type BeforeDefaultsHook interface {
+	BeforeDefaults() error
+}
+
+// AfterDefaultsHook is an interface that can be implemented by structs to compute derived fields
+// after their tag defaults are applied, it is called for every struct in the tree loaded from tags.
+type AfterDefaultsHook interface {
+	AfterDefaults() error
+}
+
 // Load initializes members in a struct referenced by a pointer.
 // Maps and slices are initialized by `make` and other primitive types are set with default values.
 // `ptr` should be a struct pointer
-func Load[T any](ptr *T) error {
-	if ok, err := LoadInterface(ptr, any(nil)); ok {
-		return err
-	}
-	return LoadStruct(ptr)
+func Load[T any](ptr *T, opts ...Option) error {
+	return defaultLoader.with(opts...).Load(ptr)
 }
 
 // MustLoad initializes members in a struct referenced by a pointer.
 // Maps and slices are initialized by `make` and other primitive types are set with default values.
 // `ptr` should be a struct pointer
-func MustLoad[T any](ptr *T) {
-	if err := Load(ptr); err != nil {
+func MustLoad[T any](ptr *T, opts ...Option) {
+	if err := Load(ptr, opts...); err != nil {
 		panic(err)
 	}
 }
@@ -41,11 +56,13 @@ func MustLoad[T any](ptr *T) {
 // LoadWithOption initializes members in a struct referenced by a pointer.
 // Maps and slices are initialized by `make` and other primitive types are set with default values.
 // `ptr` should be a struct pointer
-func LoadWithOption[T any, P any](ptr *T, arg P) error {
+func LoadWithOption[T any, P any](ptr *T, arg P, opts ...Option) error {
 	if ok, err := LoadInterface(ptr, arg); ok {
 		return err
 	}
-	return LoadStruct(ptr)
+	return defaultLoader.with(opts...).setDefaults(ptr, func(ptr any) (bool, error) {
+		return LoadInterface(ptr, arg)
+	})
 }
 
 // LoadInterface initializes members in a struct referenced by a pointer.
@@ -64,9 +81,11 @@ func LoadInterface[P any](ptr any, arg P) (bool, error) {
 
 // LoadStruct initializes members in a struct referenced by a pointer.
 // Maps and slices are initialized by `make` and other primitive types are set with default values.
+// Nested structs, slice elements and map values implementing DefaultLoader are loaded by their own Default,
+// the struct referenced by `ptr` itself is always loaded from its tags.
 // `ptr` should be a struct pointer
-func LoadStruct(ptr any) error {
-	return setDefaults(ptr)
+func LoadStruct(ptr any, opts ...Option) error {
+	return defaultLoader.with(opts...).LoadStruct(ptr)
 }
 
 // Pointer creates a pointer to a value.
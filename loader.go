@@ -0,0 +1,139 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTagName = "default"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Loader loads default values from struct tags, it is safe for concurrent use.
+// The package level functions use a Loader built without options.
+type Loader struct {
+	tagName   string
+	profile   string
+	strict    bool
+	allErrors bool
+	mode      Mode
+	maxDepth  int
+	parsers   map[reflect.Type]parserFunc
+	factories map[factoryKey]factoryFunc
+	units     map[reflect.Type]string
+	hooks     []Hook
+	lookupEnv LookupEnvFunc
+	now       func() time.Time
+
+	// plans caches a *structPlan per planKey, so tags are only read and parsed once.
+	plans *sync.Map
+}
+
+var defaultLoader = NewLoader()
+
+// NewLoader creates a Loader configured by opts.
+func NewLoader(opts ...Option) *Loader {
+	l := &Loader{
+		tagName:   defaultTagName,
+		lookupEnv: os.LookupEnv,
+		now:       time.Now,
+		plans:     &sync.Map{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// with returns a copy of the Loader with opts applied.
+// The copy shares the plan cache unless opts change how tags are compiled.
+func (l *Loader) with(opts ...Option) *Loader {
+	if len(opts) == 0 {
+		return l
+	}
+	c := *l
+	c.parsers = make(map[reflect.Type]parserFunc, len(l.parsers))
+	for t, p := range l.parsers {
+		c.parsers[t] = p
+	}
+	c.factories = make(map[factoryKey]factoryFunc, len(l.factories))
+	for k, f := range l.factories {
+		c.factories[k] = f
+	}
+	c.units = make(map[reflect.Type]string, len(l.units))
+	for t, u := range l.units {
+		c.units[t] = u
+	}
+	c.hooks = append([]Hook(nil), l.hooks...)
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// Load initializes members in a struct referenced by a pointer.
+// When `ptr` implements DefaultLoader its Default method is used instead of the tags,
+// or its DefaultProfile method when a profile is set and it implements DefaultProfileLoader.
+// `ptr` should be a struct pointer
+func (l *Loader) Load(ptr any) error {
+	if ok, err := l.loadInterfaceNoArg(ptr); ok {
+		return err
+	}
+	return l.LoadStruct(ptr)
+}
+
+// LoadWithOption initializes members in a struct referenced by a pointer.
+// `arg` is passed to every DefaultOptionLoader whose Default accepts its type.
+// `ptr` should be a struct pointer
+func (l *Loader) LoadWithOption(ptr any, arg any) error {
+	loadInterface := func(ptr any) (bool, error) {
+		return loadInterfaceValue(ptr, arg)
+	}
+	if ok, err := loadInterface(ptr); ok {
+		return err
+	}
+	return l.setDefaults(ptr, loadInterface)
+}
+
+// LoadStruct initializes members in a struct referenced by a pointer.
+// Nested structs, slice elements and map values implementing DefaultLoader are loaded by their own Default,
+// the struct referenced by `ptr` itself is always loaded from its tags.
+// `ptr` should be a struct pointer
+func (l *Loader) LoadStruct(ptr any) error {
+	return l.setDefaults(ptr, l.loadInterfaceNoArg)
+}
+
+// loadInterfaceNoArg is LoadInterface without an argument, DefaultProfileLoader is preferred when a profile is set.
+func (l *Loader) loadInterfaceNoArg(ptr any) (bool, error) {
+	if p, ok := ptr.(DefaultProfileLoader); ok && l.profile != "" {
+		return true, p.DefaultProfile(l.profile)
+	}
+	return LoadInterface(ptr, any(nil))
+}
+
+// loadInterfaceValue is LoadInterface for an argument whose type is only known at runtime.
+func loadInterfaceValue(ptr any, arg any) (bool, error) {
+	if ok, err := LoadInterface(ptr, arg); ok || arg == nil {
+		return ok, err
+	}
+	m := reflect.ValueOf(ptr).MethodByName("Default")
+	if !m.IsValid() {
+		return false, nil
+	}
+	mt := m.Type()
+	av := reflect.ValueOf(arg)
+	if mt.NumIn() != 1 || mt.NumOut() != 1 || mt.Out(0) != errorType || !av.Type().AssignableTo(mt.In(0)) {
+		return false, nil
+	}
+	if err := m.Call([]reflect.Value{av})[0].Interface(); err != nil {
+		return true, err.(error)
+	}
+	return true, nil
+}
@@ -0,0 +1,269 @@
+package dl
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type loaderConfig struct {
+	Name    string `default:"default" cfgdefault:"custom"`
+	Port    int    `default:"80" cfgdefault:"8080"`
+	Upper   upper  `cfgdefault:"loud"`
+	Child   Child
+	Options OptionPlugin
+}
+
+type upper string
+
+type profiled struct {
+	Profile string
+}
+
+func (p *profiled) Default() error {
+	p.Profile = "default"
+	return nil
+}
+
+func (p *profiled) DefaultProfile(profile string) error {
+	p.Profile = profile
+	return nil
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("tag name", func(t *testing.T) {
+		l := NewLoader(WithTagName("cfgdefault"))
+		c := &loaderConfig{}
+		if err := l.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Name != "custom" || c.Port != 8080 {
+			t.Errorf("it should read the custom tag, got %+v", c)
+		}
+		if c.Child.Name != "" {
+			t.Errorf("it should not read the default tag, got %s", c.Child.Name)
+		}
+
+		c = &loaderConfig{}
+		if err := Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Name != "default" || c.Port != 80 {
+			t.Errorf("it should not share plans between tag names, got %+v", c)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		l := NewLoader(WithStrict(true))
+		err := l.LoadStruct(&struct {
+			Port int `default:"80a"`
+		}{})
+		var e *ParseError
+		if !errors.As(err, &e) {
+			t.Errorf("it should return a *ParseError, got %v", err)
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		c := &loaderConfig{Name: "set", Port: 1, Child: Child{Age: 1}}
+		if err := NewLoader(WithMode(Overwrite)).Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Name != "default" || c.Port != 80 || c.Child.Age != 20 {
+			t.Errorf("it should reset tagged fields, got %+v", c)
+		}
+
+		c = &loaderConfig{Name: "set"}
+		if err := NewLoader().Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Name != "set" {
+			t.Errorf("it should only fill zero fields by default, got %s", c.Name)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		type server struct {
+			Host string `default:"localhost"`
+			Port int    `default:"80"`
+		}
+		type config struct {
+			Name    string            `default:"default"`
+			Labels  map[string]string `default:"{\"env\": \"dev\", \"team\": \"core\"}"`
+			Hosts   []string          `default:"[\"a\", \"b\"]"`
+			Servers []server          `default:"[{\"Host\": \"a\"}]"`
+			Server  *server
+			Origin  server `default:"{\"Host\": \"origin\"}"`
+		}
+
+		c := &config{
+			Name:    "set",
+			Labels:  map[string]string{"env": "prod", "user": "dl"},
+			Hosts:   []string{"b", "c"},
+			Servers: []server{{Host: "a", Port: 80}},
+			Server:  &server{Host: "set"},
+		}
+		if err := NewLoader(WithMode(Merge)).Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Name != "set" {
+			t.Errorf("it should keep non-initial scalars, got %s", c.Name)
+		}
+		if len(c.Labels) != 3 || c.Labels["env"] != "prod" || c.Labels["team"] != "core" || c.Labels["user"] != "dl" {
+			t.Errorf("it should add the missing default keys, got %v", c.Labels)
+		}
+		if strings.Join(c.Hosts, ",") != "b,c,a" {
+			t.Errorf("it should append the missing default elements, got %v", c.Hosts)
+		}
+		if len(c.Servers) != 1 {
+			t.Errorf("it should compare elements after their defaults are applied, got %v", c.Servers)
+		}
+		if c.Server.Host != "set" || c.Server.Port != 80 {
+			t.Errorf("it should merge into pointed structs, got %+v", c.Server)
+		}
+
+		nested := &struct {
+			N [][]string `default:"[[\"a\"]]"`
+		}{N: [][]string{{"z"}}}
+		if err := NewLoader(WithMode(Merge), WithStrict(true)).Load(nested); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if len(nested.N) != 2 || nested.N[0][0] != "z" || nested.N[1][0] != "a" {
+			t.Errorf("it should merge nested slices, got %v", nested.N)
+		}
+
+		o := &config{Origin: server{Port: 1}}
+		if err := NewLoader(WithMode(Overwrite)).Load(o); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if o.Origin != (server{Host: "origin", Port: 80}) {
+			t.Errorf("it should reset a tagged struct in overwrite mode, got %+v", o.Origin)
+		}
+	})
+
+	t.Run("profile", func(t *testing.T) {
+		type log struct {
+			Level string `default:"info" default.dev:"debug" default.prod:"warn"`
+			Trace bool   `default.dev:"true"`
+			Child Child
+		}
+		c := &log{}
+		if err := Load(c, WithProfile("prod")); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Level != "warn" || c.Trace || c.Child.Name != "Tom" {
+			t.Errorf("it should read the tags of the profile, got %+v", c)
+		}
+
+		c = &log{}
+		if err := Load(c, WithProfile("staging")); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Level != "info" {
+			t.Errorf("it should fall back to the default tag, got %s", c.Level)
+		}
+		c = &log{}
+		if err := Load(c, WithProfile("dev")); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Level != "debug" || !c.Trace {
+			t.Errorf("it should not share plans between profiles, got %+v", c)
+		}
+		if l := defaultLoader.with(WithProfile("dev")); l.plans != defaultLoader.plans {
+			t.Errorf("it should keep the plan cache of the loader")
+		}
+		if _, ok := defaultLoader.plans.Load(planKey{typ: reflect.TypeOf(log{}), tagName: defaultTagName, profile: "dev"}); !ok {
+			t.Errorf("it should cache the plans of profiles")
+		}
+
+		p := &profiled{}
+		if err := Load(p, WithProfile("prod")); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if p.Profile != "prod" {
+			t.Errorf("it should call DefaultProfile, got %q", p.Profile)
+		}
+		if err := Load(p); err != nil || p.Profile != "default" {
+			t.Errorf("it should call Default without a profile, got %q, %v", p.Profile, err)
+		}
+	})
+
+	t.Run("max depth", func(t *testing.T) {
+		err := NewLoader(WithMaxDepth(1)).Load(&loaderConfig{})
+		if !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("it should return ErrMaxDepth, got %v", err)
+		}
+		if err := NewLoader(WithMaxDepth(2)).Load(&loaderConfig{}); err != nil {
+			t.Errorf("it should not return an error: %v", err)
+		}
+	})
+
+	t.Run("parser", func(t *testing.T) {
+		l := NewLoader(WithTagName("cfgdefault"), WithParser(func(s string) (upper, error) {
+			return upper(strings.ToUpper(s)), nil
+		}))
+		c := &loaderConfig{}
+		if err := l.Load(c); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Upper != "LOUD" {
+			t.Errorf("it should use the parser, got %s", c.Upper)
+		}
+	})
+
+	t.Run("hook", func(t *testing.T) {
+		var names []string
+		l := NewLoader(WithHook(func(ptr any) error {
+			if c, ok := ptr.(*Child); ok {
+				names = append(names, c.Name)
+			}
+			return nil
+		}))
+		if err := l.Load(&loaderConfig{}); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if len(names) != 1 || names[0] != "Tom" {
+			t.Errorf("it should call the hook after the defaults are applied, got %v", names)
+		}
+
+		err := NewLoader(WithHook(func(ptr any) error {
+			return errors.New("hook failed")
+		})).Load(&loaderConfig{})
+		var e *FieldError
+		if !errors.As(err, &e) || e.Path != "loaderConfig.Child" {
+			t.Errorf("it should report the hook error with its path, got %v", err)
+		}
+	})
+
+	t.Run("load with option", func(t *testing.T) {
+		c := &loaderConfig{}
+		if err := NewLoader().LoadWithOption(c, "prod"); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Options.Env != "prod" {
+			t.Errorf("it should pass the argument to DefaultOptionLoader, got %s", c.Options.Env)
+		}
+
+		c = &loaderConfig{}
+		if err := NewLoader().LoadWithOption(c, 1); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Options.Env != "tag" {
+			t.Errorf("it should use tags when the argument type does not match, got %s", c.Options.Env)
+		}
+	})
+
+	t.Run("package options", func(t *testing.T) {
+		c := &loaderConfig{}
+		if err := Load(c, WithTagName("cfgdefault")); err != nil {
+			t.Fatalf("it should not return an error: %v", err)
+		}
+		if c.Name != "custom" {
+			t.Errorf("it should apply options to the default loader, got %s", c.Name)
+		}
+		if defaultLoader.tagName != defaultTagName {
+			t.Errorf("it should not change the default loader")
+		}
+	})
+}
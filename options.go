@@ -0,0 +1,130 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Option configures a Loader.
+type Option func(*Loader)
+
+// Mode decides which fields receive their default value.
+type Mode int
+
+const (
+	// FillZero sets defaults only on fields holding the zero value of their type.
+	FillZero Mode = iota
+	// Overwrite resets every tagged field to its default value.
+	Overwrite
+	// Merge works like FillZero, and also adds the default keys missing in non-empty maps
+	// and appends the default elements missing in non-empty slices.
+	Merge
+)
+
+// Hook is called with a pointer to every struct in the tree after its tag defaults are applied.
+type Hook func(ptr any) error
+
+// WithTagName reads default values from the tag `name` instead of `default`.
+func WithTagName(name string) Option {
+	return func(l *Loader) {
+		l.tagName = name
+	}
+}
+
+// WithProfile reads default values from the tag of the profile, like `default.prod` for `prod`,
+// fields without it fall back to the tag of WithTagName.
+func WithProfile(profile string) Option {
+	return func(l *Loader) {
+		l.profile = profile
+	}
+}
+
+// WithStrict reports tags that cannot be parsed into their field kind as errors
+// instead of silently leaving the field untouched.
+func WithStrict(strict bool) Option {
+	return func(l *Loader) {
+		l.strict = strict
+	}
+}
+
+// WithAllErrors keeps loading after a field fails and returns every failure as FieldErrors.
+func WithAllErrors(all bool) Option {
+	return func(l *Loader) {
+		l.allErrors = all
+	}
+}
+
+// WithMode selects which fields receive their default value, FillZero by default.
+func WithMode(mode Mode) Option {
+	return func(l *Loader) {
+		l.mode = mode
+	}
+}
+
+// WithMaxDepth limits how deep nested structs are walked, 0 means no limit.
+func WithMaxDepth(depth int) Option {
+	return func(l *Loader) {
+		l.maxDepth = depth
+	}
+}
+
+// WithParser parses tag values of type T by fn instead of by kind, see RegisterParser.
+func WithParser[T any](fn func(string) (T, error)) Option {
+	t, p := newParser(fn)
+	return func(l *Loader) {
+		if l.parsers == nil {
+			l.parsers = make(map[reflect.Type]parserFunc)
+		}
+		l.parsers[t] = p
+		l.plans = &sync.Map{}
+	}
+}
+
+// WithUnit parses the integer fields of type T by unit, see RegisterUnit.
+func WithUnit[T any](unit string) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(l *Loader) {
+		if l.units == nil {
+			l.units = make(map[reflect.Type]string)
+		}
+		l.units[t] = unit
+		l.plans = &sync.Map{}
+	}
+}
+
+// WithLookupEnv looks up the variables of `env` tags and `${NAME}` references by fn instead of os.LookupEnv.
+func WithLookupEnv(fn LookupEnvFunc) Option {
+	return func(l *Loader) {
+		l.lookupEnv = fn
+	}
+}
+
+// WithClock evaluates the `now` and `today` keywords of time.Time defaults against now instead of time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(l *Loader) {
+		l.now = now
+	}
+}
+
+// WithFactory creates the value of interface fields with type I whose default tag is `name` by fn,
+// see RegisterFactory.
+func WithFactory[I any](name string, fn func() I) Option {
+	k, f := newFactory(name, fn)
+	return func(l *Loader) {
+		if l.factories == nil {
+			l.factories = make(map[factoryKey]factoryFunc)
+		}
+		l.factories[k] = f
+	}
+}
+
+// WithHook adds a Hook called after the tag defaults of each struct are applied.
+func WithHook(hook Hook) Option {
+	return func(l *Loader) {
+		l.hooks = append(l.hooks, hook)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+type parserFunc func(string) (reflect.Value, error)
+
+// parsers holds the parserFunc registered by RegisterParser for each type.
+var parsers sync.Map
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// RegisterParser registers fn to parse tag values of type T for every Loader.
+// It is consulted before the field kind, so it works for types without encoding.TextUnmarshaler,
+// pointers to T, and elements of slices and values of maps holding T.
+// A parser set by WithParser takes precedence over the registered one.
+func RegisterParser[T any](fn func(string) (T, error)) {
+	t, p := newParser(fn)
+	parsers.Store(t, p)
+}
+
+func newParser[T any](fn func(string) (T, error)) (reflect.Type, parserFunc) {
+	return reflect.TypeOf((*T)(nil)).Elem(), func(s string) (reflect.Value, error) {
+		v, err := fn(s)
+		return reflect.ValueOf(&v).Elem(), err
+	}
+}
+
+// parserFor returns the parser of the Loader or the registered one for t.
+func (l *Loader) parserFor(t reflect.Type) parserFunc {
+	if p, ok := l.parsers[t]; ok {
+		return p
+	}
+	if p, ok := parsers.Load(t); ok {
+		return p.(parserFunc)
+	}
+	return nil
+}
+
+// hasParser reports whether t, or the type t points to, has a parser.
+func (l *Loader) hasParser(t reflect.Type) bool {
+	for {
+		if l.parserFor(t) != nil {
+			return true
+		}
+		if t.Kind() != reflect.Ptr {
+			return false
+		}
+		t = t.Elem()
+	}
+}
+
+// parseByParser sets the field by the parser registered for its type.
+func (w *walker) parseByParser(field reflect.Value, defaultVal string) (bool, error) {
+	parse := w.l.parserFor(field.Type())
+	if parse == nil || defaultVal == "" {
+		return false, nil
+	}
+	val, err := parse(defaultVal)
+	if err != nil {
+		if w.l.strict {
+			return true, w.fail(field, defaultVal, parseError(field.Kind(), defaultVal, err))
+		}
+		return true, nil
+	}
+	field.Set(val)
+	return true, nil
+}
+
+// setElemByParser sets a slice element or map value from its json form,
+// strings are unquoted before they are passed to the parser.
+func (w *walker) setElemByParser(elem reflect.Value, raw json.RawMessage) error {
+	s := string(raw)
+	var unquoted string
+	if err := json.Unmarshal(raw, &unquoted); err == nil {
+		s = unquoted
+	}
+	for w.l.parserFor(elem.Type()) == nil && elem.Kind() == reflect.Ptr {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		elem = elem.Elem()
+	}
+	_, err := w.parseByParser(elem, s)
+	return err
+}
+
+// setListByParser sets a slice or an array whose element type has a parser from a json array.
+func (w *walker) setListByParser(field reflect.Value, defaultVal string) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal([]byte(defaultVal), &raws); err != nil {
+		return w.fail(field, defaultVal, err)
+	}
+	var list reflect.Value
+	if field.Kind() == reflect.Array {
+		if len(raws) > field.Len() {
+			return w.fail(field, defaultVal, arrayLengthError(len(raws), field.Len()))
+		}
+		list = reflect.New(field.Type()).Elem()
+	} else {
+		list = reflect.MakeSlice(field.Type(), len(raws), len(raws))
+	}
+	for i, raw := range raws {
+		w.path = w.path.pushIndex(i)
+		err := w.setElemByParser(list.Index(i), raw)
+		w.path = w.path.pop()
+		if err != nil {
+			return err
+		}
+	}
+	field.Set(list)
+	return nil
+}
+
+// setMapByParser sets a map whose value type has a parser from a json object.
+func (w *walker) setMapByParser(field reflect.Value, defaultVal string) error {
+	raws := reflect.New(reflect.MapOf(field.Type().Key(), rawMessageType))
+	if err := json.Unmarshal([]byte(defaultVal), raws.Interface()); err != nil {
+		return w.fail(field, defaultVal, err)
+	}
+	m := reflect.MakeMapWithSize(field.Type(), raws.Elem().Len())
+	iter := raws.Elem().MapRange()
+	for iter.Next() {
+		elem := reflect.New(field.Type().Elem()).Elem()
+		w.path = w.path.pushKey(iter.Key())
+		err := w.setElemByParser(elem, iter.Value().Interface().(json.RawMessage))
+		w.path = w.path.pop()
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(iter.Key(), elem)
+	}
+	field.Set(m)
+	return nil
+}
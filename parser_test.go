@@ -0,0 +1,109 @@
+package dl
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type Endpoints struct {
+	URL       url.URL             `default:"https://example.com/api"`
+	URLPtr    *url.URL            `default:"https://example.com/ptr"`
+	URLs      []url.URL           `default:"[\"https://a.example.com\", \"https://b.example.com\"]"`
+	URLPtrs   []*url.URL          `default:"[\"https://c.example.com\"]"`
+	URLMap    map[string]url.URL  `default:"{\"a\": \"https://a.example.com\"}"`
+	URLPtrMap map[string]*url.URL `default:"{\"b\": \"https://b.example.com\"}"`
+}
+
+type Decimal struct {
+	Units int64
+	Nanos int32
+}
+
+func parseURL(s string) (url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return url.URL{}, err
+	}
+	return *u, nil
+}
+
+// registerParser registers fn like RegisterParser until the test ends.
+func registerParser[T any](t *testing.T, fn func(string) (T, error)) {
+	RegisterParser(fn)
+	t.Cleanup(func() { parsers.Delete(reflect.TypeOf((*T)(nil)).Elem()) })
+}
+
+func TestRegisterParser(t *testing.T) {
+	registerParser(t, parseURL)
+
+	e := &Endpoints{}
+	if err := Load(e, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if e.URL.Host != "example.com" || e.URL.Path != "/api" {
+		t.Errorf("it should parse a field, got %s", e.URL.String())
+	}
+	if e.URLPtr == nil || e.URLPtr.Path != "/ptr" {
+		t.Errorf("it should parse a pointer field, got %v", e.URLPtr)
+	}
+	if len(e.URLs) != 2 || e.URLs[1].Host != "b.example.com" {
+		t.Errorf("it should parse slice elements, got %v", e.URLs)
+	}
+	if len(e.URLPtrs) != 1 || e.URLPtrs[0] == nil || e.URLPtrs[0].Host != "c.example.com" {
+		t.Errorf("it should parse slice pointer elements, got %v", e.URLPtrs)
+	}
+	if u, ok := e.URLMap["a"]; !ok || u.Host != "a.example.com" {
+		t.Errorf("it should parse map values, got %v", e.URLMap)
+	}
+	if u, ok := e.URLPtrMap["b"]; !ok || u == nil || u.Host != "b.example.com" {
+		t.Errorf("it should parse map pointer values, got %v", e.URLPtrMap)
+	}
+}
+
+func TestParserError(t *testing.T) {
+	parse := WithParser(func(s string) (Decimal, error) {
+		units, frac, _ := strings.Cut(s, ".")
+		if units == "" {
+			return Decimal{}, errors.New("missing units")
+		}
+		d := Decimal{}
+		var err error
+		if d.Units, err = strconv.ParseInt(units, 10, 64); err != nil {
+			return Decimal{}, err
+		}
+		if frac != "" {
+			nanos, err := strconv.ParseInt(frac, 10, 32)
+			if err != nil {
+				return Decimal{}, err
+			}
+			d.Nanos = int32(nanos)
+		}
+		return d, nil
+	})
+
+	type price struct {
+		Price  Decimal   `default:"12.5"`
+		Prices []Decimal `default:"[\"1.1\", \".2\"]"`
+	}
+
+	p := &price{}
+	if err := NewLoader(parse).Load(p); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if p.Price != (Decimal{Units: 12, Nanos: 5}) {
+		t.Errorf("it should use the loader parser, got %+v", p.Price)
+	}
+	if len(p.Prices) != 2 || p.Prices[0] != (Decimal{Units: 1, Nanos: 1}) || p.Prices[1] != (Decimal{}) {
+		t.Errorf("it should keep the zero value for invalid elements in lenient mode, got %+v", p.Prices)
+	}
+
+	err := NewLoader(parse, WithStrict(true)).Load(&price{})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "price.Prices[1]" {
+		t.Errorf("it should report the failing element with its path, got %v", err)
+	}
+}
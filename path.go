@@ -0,0 +1,66 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is a field name, a slice index or a map key, rendered only when an error is reported.
+// A negative index stands for any element.
+type pathSegment struct {
+	name  string
+	index int
+	key   reflect.Value
+}
+
+func (s pathSegment) String() string {
+	switch {
+	case s.name != "":
+		return s.name
+	case s.key.IsValid():
+		return fmt.Sprintf("[%v]", s.key.Interface())
+	case s.index < 0:
+		return "[]"
+	default:
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+}
+
+// fieldPath is the dotted path of the field being loaded, like `Config.Servers[2].TLS`.
+type fieldPath []pathSegment
+
+func (p fieldPath) push(name string) fieldPath {
+	return append(p, pathSegment{name: name})
+}
+
+func (p fieldPath) pushIndex(i int) fieldPath {
+	return append(p, pathSegment{index: i})
+}
+
+func (p fieldPath) pushElem() fieldPath {
+	return append(p, pathSegment{index: -1})
+}
+
+func (p fieldPath) pushKey(key reflect.Value) fieldPath {
+	return append(p, pathSegment{key: key})
+}
+
+func (p fieldPath) pop() fieldPath {
+	return p[:len(p)-1]
+}
+
+func (p fieldPath) String() string {
+	var sb strings.Builder
+	for i, s := range p {
+		if i > 0 && s.name != "" {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
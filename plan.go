@@ -0,0 +1,164 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// structPlan is the compiled form of a struct type.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+// fieldPlan describes a single field which may receive a default value.
+type fieldPlan struct {
+	index int
+	name  string
+	// env is the variable named by the env tag which overrides the default
+	env string
+	// hidden is set for unexported embedded structs, whose exported fields are promoted
+	hidden bool
+	def    *fieldDefault
+}
+
+// fieldDefault is a default tag value, scalar values are parsed when the plan is compiled.
+type fieldDefault struct {
+	raw string
+	// expand is set when raw holds `${...}` references resolved on load
+	expand bool
+	// layout is the layout tag of time.Time fields
+	layout string
+	// unit is the unit tag of integer fields, or the unit set by WithUnit for their type,
+	// the unit registered by RegisterUnit is looked up on load as it may be registered after the plan is compiled
+	unit string
+	// ref is set when raw references other fields, like `@Server.Timeout` or `{{.Host}}:{{.Port}}`
+	ref *fieldRef
+	// typ is the type value and err were parsed for, nil if the tag is parsed on load
+	typ   reflect.Type
+	value reflect.Value
+	err   error
+}
+
+// noDefault is used for values reached without a tag, like map values.
+var noDefault = &fieldDefault{}
+
+// planKey identifies a cached plan, a struct type has a plan per tag name and profile.
+type planKey struct {
+	typ     reflect.Type
+	tagName string
+	profile string
+}
+
+func (l *Loader) planKey(t reflect.Type) planKey {
+	return planKey{typ: t, tagName: l.tagName, profile: l.profile}
+}
+
+func (l *Loader) planOf(t reflect.Type) *structPlan {
+	k := l.planKey(t)
+	if p, ok := l.plans.Load(k); ok {
+		return p.(*structPlan)
+	}
+	p, _ := l.plans.LoadOrStore(k, l.compilePlan(t))
+	return p.(*structPlan)
+}
+
+func (l *Loader) compilePlan(t reflect.Type) *structPlan {
+	p := &structPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := l.tagOf(sf)
+		env := sf.Tag.Get(envTagName)
+		if tag == "-" || (tag == "" && env == "" && !isContainer(sf.Type)) {
+			continue
+		}
+		p.fields = append(p.fields, fieldPlan{
+			index:  i,
+			name:   sf.Name,
+			env:    env,
+			hidden: sf.Anonymous && !sf.IsExported() && isStruct(sf.Type),
+			def:    l.compileDefault(sf, tag),
+		})
+	}
+	return p
+}
+
+// tagOf returns the default tag of sf, the tag of the profile takes precedence when it is set.
+func (l *Loader) tagOf(sf reflect.StructField) string {
+	if l.profile != "" {
+		if tag, ok := sf.Tag.Lookup(l.tagName + "." + l.profile); ok {
+			return tag
+		}
+	}
+	return sf.Tag.Get(l.tagName)
+}
+
+// isContainer reports whether values of t are walked for defaults even without a tag.
+func isContainer(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Array:
+		return isContainer(t.Elem())
+	default:
+		return false
+	}
+}
+
+// isStruct reports whether t is a struct or a pointer to a struct.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+func (l *Loader) compileDefault(sf reflect.StructField, raw string) *fieldDefault {
+	ref, raw := parseRef(raw)
+	def := &fieldDefault{raw: raw, ref: ref, expand: hasEnv(raw), layout: sf.Tag.Get(layoutTagName), unit: sf.Tag.Get(unitTagName)}
+	t := sf.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if def.unit == "" {
+		def.unit = l.units[t]
+	}
+	if raw == "" || def.ref != nil || def.expand || !isScalar(t.Kind()) || implementsUnmarshaler(t) || l.hasParser(t) {
+		return def
+	}
+	v := reflect.New(t).Elem()
+	def.typ = t
+	def.err = parseScalar(v, raw, def.unit)
+	def.value = v
+	return def
+}
+
+// withRaw returns a default of raw which keeps the companion tags of d, like the layout.
+func (d *fieldDefault) withRaw(raw string) *fieldDefault {
+	return &fieldDefault{raw: raw, layout: d.layout, unit: d.unit}
+}
+
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func implementsUnmarshaler(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(jsonUnmarshalerType)
+}
@@ -0,0 +1,347 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// refPattern matches a default referencing a single field, like `@Server.Timeout`.
+var refPattern = regexp.MustCompile(`^@[A-Za-z_]\w*(\.[A-Za-z_]\w*)*$`)
+
+// fieldRef is a default referencing other fields, either a single field like `@Server.Timeout`
+// or a template like `{{.Host}}:{{.Port}}`.
+type fieldRef struct {
+	// paths are the referenced fields, the only one of an `@` reference
+	paths [][]string
+	tmpl  *template.Template
+	// err is the error of parsing the template
+	err error
+}
+
+// parseRef returns the references of a default, or nil if it references no field, with the value of the default.
+// A default starting with `@@` is the literal value without the first `@`, like `@@admin` for `@admin`.
+func parseRef(raw string) (*fieldRef, string) {
+	if strings.HasPrefix(raw, "@@") {
+		return nil, raw[1:]
+	}
+	if refPattern.MatchString(raw) {
+		return &fieldRef{paths: [][]string{strings.Split(raw[1:], ".")}}, raw
+	}
+	if !strings.Contains(raw, "{{") {
+		return nil, raw
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Parse(raw)
+	if err != nil {
+		return &fieldRef{err: err}, raw
+	}
+	r := &fieldRef{tmpl: tmpl}
+	r.collect(tmpl.Tree.Root)
+	return r, raw
+}
+
+// collect adds the fields referenced by the nodes of the template,
+// nodes inside range and with are skipped as their dot is not the struct.
+func (r *fieldRef) collect(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			r.collect(c)
+		}
+	case *parse.ActionNode:
+		r.collect(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			r.collect(c)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			r.collect(arg)
+		}
+	case *parse.ChainNode:
+		r.collect(n.Node)
+	case *parse.FieldNode:
+		r.paths = append(r.paths, n.Ident)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			r.paths = append(r.paths, n.Ident[1:])
+		}
+	case *parse.IfNode:
+		r.collect(n.Pipe)
+		r.collect(n.List)
+		r.collect(n.ElseList)
+	case *parse.RangeNode:
+		r.collect(n.Pipe)
+	case *parse.WithNode:
+		r.collect(n.Pipe)
+	default:
+		// text and constants reference nothing
+	}
+}
+
+// pendingRef is a field whose default references fields which may not be loaded yet.
+type pendingRef struct {
+	field reflect.Value
+	def   *fieldDefault
+	path  fieldPath
+	// name is the name of the field, which references the field of an ancestor with the same name
+	name string
+	// scopes are the structs enclosing the field, the innermost last, references are looked up from it outwards
+	scopes []reflect.Value
+	// anchor is the index in the stack of the outermost struct holding a referenced field,
+	// the reference is resolved when that struct is loaded
+	anchor int
+	// targets are the referenced fields, found when the reference is resolved
+	targets []reflect.Value
+}
+
+// deferRef records a field whose default references other fields, it is set once its struct is loaded.
+func (w *walker) deferRef(field reflect.Value, def *fieldDefault) error {
+	if def.ref.err != nil {
+		return w.failRef(field, def, def.ref.err)
+	}
+	scopes := make([]reflect.Value, len(w.stack))
+	for i, s := range w.stack {
+		scopes[i] = s.value
+	}
+	r := &pendingRef{
+		field:  field,
+		def:    def,
+		path:   append(fieldPath(nil), w.path...),
+		name:   w.path[len(w.path)-1].name,
+		scopes: scopes,
+		anchor: len(scopes) - 1,
+	}
+	for _, p := range def.ref.paths {
+		i := r.scopeOf(p[0])
+		if i < 0 {
+			return w.failRef(field, def, fmt.Errorf("%w %q referenced", ErrUnknownField, strings.Join(p, ".")))
+		}
+		if i < r.anchor {
+			r.anchor = i
+		}
+	}
+	w.refs = append(w.refs, r)
+	return nil
+}
+
+// scopeOf returns the index of the innermost scope holding an exported field named name, or -1.
+// The field of r is skipped, so `Timeout` references the field of an ancestor from a field named Timeout.
+func (r *pendingRef) scopeOf(name string) int {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if i == len(r.scopes)-1 && name == r.name {
+			continue
+		}
+		if sf, ok := r.scopes[i].Type().FieldByName(name); ok && sf.IsExported() {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup returns the field at path, nil pointers on the way are reported as errors.
+func (r *pendingRef) lookup(path []string) (reflect.Value, error) {
+	i := r.scopeOf(path[0])
+	if i < 0 {
+		return reflect.Value{}, fmt.Errorf("%w %q referenced", ErrUnknownField, strings.Join(path, "."))
+	}
+	v := r.scopes[i]
+	for j, name := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf("nil %s in reference %q", strings.Join(path[:j], "."), strings.Join(path, "."))
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("%w %q referenced", ErrUnknownField, strings.Join(path, "."))
+		}
+		sf, ok := v.Type().FieldByName(name)
+		if !ok || !sf.IsExported() {
+			return reflect.Value{}, fmt.Errorf("%w %q referenced", ErrUnknownField, strings.Join(path, "."))
+		}
+		for k, index := range sf.Index {
+			if k > 0 && v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, fmt.Errorf("nil embedded struct in reference %q", strings.Join(path, "."))
+				}
+				v = v.Elem()
+			}
+			v = v.Field(index)
+			if !v.CanInterface() {
+				v = embeddedField(v)
+			}
+		}
+	}
+	return v, nil
+}
+
+// dependsOn reports whether r references the field of d, or a struct holding it, or a field inside it.
+func (r *pendingRef) dependsOn(d *pendingRef) bool {
+	for _, t := range r.targets {
+		if overlaps(t, d.field) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether the memory of the addressable values a and b overlaps.
+func overlaps(a, b reflect.Value) bool {
+	aStart, bStart := a.UnsafeAddr(), b.UnsafeAddr()
+	return aStart < bStart+b.Type().Size() && bStart < aStart+a.Type().Size()
+}
+
+// resolveRefs sets the pending fields selected by in, in the order of their dependencies.
+// A field depending on a pending field which is not selected keeps waiting for it.
+func (w *walker) resolveRefs(in func(r *pendingRef) bool) error {
+	var batch, rest []*pendingRef
+	for _, r := range w.refs {
+		if in(r) {
+			batch = append(batch, r)
+		} else {
+			rest = append(rest, r)
+		}
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	for _, r := range batch {
+		r.targets = r.targets[:0]
+		for _, p := range r.def.ref.paths {
+			if t, err := r.lookup(p); err == nil {
+				r.targets = append(r.targets, t)
+			}
+		}
+	}
+	for waiting := true; waiting; {
+		waiting = false
+		for i := 0; i < len(batch); i++ {
+			for _, d := range rest {
+				if batch[i].dependsOn(d) {
+					rest = append(rest, batch[i])
+					batch = append(batch[:i], batch[i+1:]...)
+					i--
+					waiting = true
+					break
+				}
+			}
+		}
+	}
+	w.refs = rest
+
+	const (
+		resolving = 1
+		resolved  = 2
+	)
+	state := make(map[*pendingRef]int, len(batch))
+	var chain []string
+	var resolve func(r *pendingRef) error
+	resolve = func(r *pendingRef) error {
+		switch state[r] {
+		case resolving:
+			err := fmt.Errorf("%w: %s -> %s", ErrCycle, strings.Join(chain, " -> "), r.path)
+			return w.failAt(r, err)
+		case resolved:
+			return nil
+		}
+		state[r] = resolving
+		chain = append(chain, r.path.String())
+		for _, d := range batch {
+			if r.dependsOn(d) {
+				if err := resolve(d); err != nil {
+					return err
+				}
+			}
+		}
+		chain = chain[:len(chain)-1]
+		state[r] = resolved
+		return w.resolveRef(r)
+	}
+	for _, r := range batch {
+		if err := resolve(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failRef reports a reference which cannot be resolved in strict mode,
+// lenient loading sets the default as a literal value, like a tag which is not a reference.
+func (w *walker) failRef(field reflect.Value, def *fieldDefault, err error) error {
+	if w.l.strict {
+		return w.fail(field, def.raw, err)
+	}
+	raw := def.raw
+	if def.expand {
+		raw = expandEnv(raw, w.l.lookupEnv)
+	}
+	return w.setField(field, def.withRaw(raw))
+}
+
+// failAt reports err for the field of r.
+func (w *walker) failAt(r *pendingRef, err error) error {
+	path := w.path
+	w.path = r.path
+	defer func() { w.path = path }()
+	return w.fail(r.field, r.def.raw, err)
+}
+
+// resolveRef sets the field of r from the referenced fields, a single field of the same type is copied,
+// other values are formatted and parsed like a default tag.
+func (w *walker) resolveRef(r *pendingRef) error {
+	path := w.path
+	w.path = r.path
+	defer func() { w.path = path }()
+
+	ref := r.def.ref
+	var raw string
+	if ref.tmpl == nil {
+		target, err := r.lookup(ref.paths[0])
+		if err != nil {
+			return w.failRef(r.field, r.def, err)
+		}
+		switch target.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			// the value is not shared between the fields
+		default:
+			if target.Type() == r.field.Type() {
+				if isInitialValue(r.field) || w.l.mode == Overwrite {
+					r.field.Set(target)
+				}
+				return nil
+			}
+		}
+		raw = formatValue(target)
+	} else {
+		data := make(map[string]any, len(ref.paths))
+		for _, p := range ref.paths {
+			v, err := r.lookup(p[:1])
+			if err != nil {
+				return w.failRef(r.field, r.def, err)
+			}
+			data[p[0]] = v.Interface()
+		}
+		var sb strings.Builder
+		if err := ref.tmpl.Execute(&sb, data); err != nil {
+			return w.failRef(r.field, r.def, err)
+		}
+		raw = sb.String()
+	}
+	if r.def.expand {
+		raw = expandEnv(raw, w.l.lookupEnv)
+	}
+	return w.setField(r.field, r.def.withRaw(raw))
+}
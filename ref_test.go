@@ -0,0 +1,125 @@
+package dl
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type refServer struct {
+	Addr    string        `default:"{{.Host}}:{{.Port}}"`
+	Host    string        `default:"localhost"`
+	Port    int           `default:"8080"`
+	Timeout time.Duration `default:"@Timeout"`
+	URL     string        `default:"http://{{.Addr}}/{{.Name}}"`
+}
+
+type refConfig struct {
+	Server  refServer
+	Name    string               `default:"app"`
+	Timeout time.Duration        `default:"5s"`
+	Read    time.Duration        `default:"@Server.Timeout"`
+	Ports   []int                `default:"[1, 2]"`
+	Copies  []int                `default:"@Ports"`
+	Label   string               `default:"{{if .Name}}{{.Name}}-{{.Server.Port}}{{end}}"`
+	Servers map[string]refServer `default:"{\"api\": {\"Port\": 9090}}"`
+}
+
+func TestReferences(t *testing.T) {
+	c := &refConfig{}
+	if err := Load(c, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Server.Addr != "localhost:8080" {
+		t.Errorf("it should render templates after the referenced fields, got %s", c.Server.Addr)
+	}
+	if c.Server.Timeout != 5*time.Second || c.Read != 5*time.Second {
+		t.Errorf("it should copy ancestor fields, got %s %s", c.Server.Timeout, c.Read)
+	}
+	if c.Server.URL != "http://localhost:8080/app" {
+		t.Errorf("it should resolve templates referencing siblings and ancestors, got %s", c.Server.URL)
+	}
+	if len(c.Copies) != 2 || c.Copies[1] != 2 {
+		t.Errorf("it should copy slices, got %v", c.Copies)
+	}
+	c.Copies[0] = 3
+	if c.Ports[0] != 1 {
+		t.Errorf("it should not share slices, got %v", c.Ports)
+	}
+	if c.Label != "app-8080" {
+		t.Errorf("it should render actions, got %s", c.Label)
+	}
+	if s := c.Servers["api"]; s.URL != "http://localhost:9090/app" || s.Timeout != 5*time.Second {
+		t.Errorf("it should resolve references in map values, got %+v", s)
+	}
+
+	c = &refConfig{Server: refServer{Host: "example.com"}, Timeout: time.Second}
+	if err := Load(c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Server.Addr != "example.com:8080" || c.Read != time.Second {
+		t.Errorf("it should reference the values set before loading, got %s %s", c.Server.Addr, c.Read)
+	}
+
+	c.Server.Timeout = 0
+	if err := ResetField(c, "Server.Timeout"); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Server.Timeout != time.Second {
+		t.Errorf("it should resolve references when a field is reset, got %s", c.Server.Timeout)
+	}
+}
+
+func TestReferenceErrors(t *testing.T) {
+	err := Load(&struct {
+		A string `default:"@B"`
+		B string `default:"{{.C}}"`
+		C string `default:"@A"`
+	}{})
+	if !errors.Is(err, ErrCycle) {
+		t.Errorf("it should return ErrCycle, got %v", err)
+	}
+
+	err = Load(&struct {
+		A string `default:"@Missing"`
+	}{}, WithStrict(true))
+	if !errors.Is(err, ErrUnknownField) {
+		t.Errorf("it should return ErrUnknownField, got %v", err)
+	}
+
+	err = Load(&struct {
+		A string `default:"{{.B"`
+		B string
+	}{}, WithStrict(true))
+	var e *FieldError
+	if !errors.As(err, &e) || e.Path != "A" {
+		t.Errorf("it should report template errors with the field path, got %v", err)
+	}
+
+	l := &struct {
+		User     string `default:"@admin"`
+		Greeting string `default:"Hello {{.Name}}"`
+		Broken   string `default:"{{.B"`
+		Other    int    `default:"3"`
+	}{}
+	if err := Load(l); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if l.User != "@admin" || l.Greeting != "Hello {{.Name}}" || l.Broken != "{{.B" || l.Other != 3 {
+		t.Errorf("it should keep unresolved references as literals in lenient mode, got %+v", l)
+	}
+
+	escaped := &struct {
+		Name string `default:"@@Name"`
+	}{}
+	if err := Load(escaped, WithStrict(true)); err != nil || escaped.Name != "@Name" {
+		t.Errorf("it should unescape @@, got %s, %v", escaped.Name, err)
+	}
+
+	c := &struct {
+		Email string `default:"@ host"`
+	}{}
+	if err := Load(c); err != nil || c.Email != "@ host" {
+		t.Errorf("it should keep other values starting with @, got %s, %v", c.Email, err)
+	}
+}
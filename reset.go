@@ -0,0 +1,115 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Reset sets the struct referenced by `ptr` to its zero value and loads its defaults again.
+// `ptr` should be a struct pointer
+func Reset[T any](ptr *T, opts ...Option) error {
+	return defaultLoader.with(opts...).Reset(ptr)
+}
+
+// ResetField sets the field named by the dotted `path`, like `Server.Timeout`,
+// to its zero value and loads its default again, nil pointers on the way are allocated.
+// `ptr` should be a struct pointer
+func ResetField[T any](ptr *T, path string, opts ...Option) error {
+	return defaultLoader.with(opts...).ResetField(ptr, path)
+}
+
+// Reset sets the struct referenced by `ptr` to its zero value and loads its defaults again.
+// `ptr` should be a struct pointer
+func (l *Loader) Reset(ptr any) error {
+	v, err := structOf(ptr)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.Zero(v.Type()))
+	return l.Load(ptr)
+}
+
+// ResetField sets the field named by the dotted `path`, like `Server.Timeout`,
+// to its zero value and loads its default again, nil pointers on the way are allocated.
+// `ptr` should be a struct pointer
+func (l *Loader) ResetField(ptr any, path string) error {
+	v, err := structOf(ptr)
+	if err != nil {
+		return err
+	}
+
+	w := newWalker(l, v.Type(), l.loadInterfaceNoArg)
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		// the structs on the way are the scopes of references
+		w.stack = append(w.stack, visit{typ: v.Type(), addr: v.Addr().Pointer(), value: v, depth: len(w.path)})
+		field, f, ok := l.fieldByName(v, name)
+		if !ok {
+			return fmt.Errorf("%w %q in %s", ErrUnknownField, path, v.Type())
+		}
+		w.path = w.path.push(name)
+		if i == len(names)-1 {
+			field.Set(reflect.Zero(field.Type()))
+			if f == nil {
+				return nil
+			}
+			def := f.def
+			if f.env != "" || def.expand {
+				def = l.resolveDefault(f)
+			}
+			if err := w.setDefault(field, def); err != nil {
+				return err
+			}
+			if err := w.resolveRefs(func(*pendingRef) bool { return true }); err != nil {
+				return err
+			}
+			return w.err()
+		}
+
+		for field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.Struct {
+			return fmt.Errorf("%w %q in %s", ErrUnknownField, path, v.Type())
+		}
+		v = field
+	}
+	return nil
+}
+
+// fieldByName returns the exported field of the struct v named name with its plan,
+// which is nil when the field has no default. Promoted fields are found through their embedded structs.
+func (l *Loader) fieldByName(v reflect.Value, name string) (reflect.Value, *fieldPlan, bool) {
+	sf, ok := v.Type().FieldByName(name)
+	if !ok || !sf.IsExported() {
+		return reflect.Value{}, nil, false
+	}
+	last := len(sf.Index) - 1
+	for _, i := range sf.Index[:last] {
+		v = v.Field(i)
+		if !v.CanSet() {
+			v = embeddedField(v)
+		}
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+	}
+
+	fields := l.planOf(v.Type()).fields
+	for i := range fields {
+		if fields[i].index == sf.Index[last] {
+			return v.Field(sf.Index[last]), &fields[i], true
+		}
+	}
+	return v.Field(sf.Index[last]), nil, true
+}
@@ -0,0 +1,92 @@
+package dl
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type resetServer struct {
+	Host    string        `default:"localhost"`
+	Timeout time.Duration `default:"5s"`
+	Labels  map[string]string
+}
+
+type resetLimits struct {
+	Burst int `default:"10"`
+}
+
+type resetConfig struct {
+	resetLimits
+	Name   string `default:"app"`
+	Debug  bool
+	Server resetServer
+	Backup *resetServer
+}
+
+func TestReset(t *testing.T) {
+	c := &resetConfig{
+		resetLimits: resetLimits{Burst: 1},
+		Name:        "set",
+		Debug:       true,
+		Server:      resetServer{Host: "example.com", Labels: map[string]string{"a": "b"}},
+	}
+	if err := Reset(c); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Name != "app" || c.Debug || c.Burst != 10 || c.Server.Host != "localhost" || c.Server.Labels != nil {
+		t.Errorf("it should zero and load the struct again, got %+v", c)
+	}
+
+	var e *invalidTypeErr
+	if err := NewLoader().Reset(resetConfig{}); !errors.As(err, &e) {
+		t.Errorf("it should return an invalid type error for a non-pointer, got %v", err)
+	}
+}
+
+func TestResetField(t *testing.T) {
+	c := &resetConfig{
+		resetLimits: resetLimits{Burst: 1},
+		Name:        "set",
+		Debug:       true,
+		Server:      resetServer{Host: "example.com", Timeout: time.Second},
+	}
+	for _, path := range []string{"Server.Timeout", "Burst", "Debug", "Backup.Host"} {
+		if err := ResetField(c, path); err != nil {
+			t.Fatalf("it should not return an error for %s: %v", path, err)
+		}
+	}
+	if c.Server.Timeout != 5*time.Second || c.Server.Host != "example.com" {
+		t.Errorf("it should only reset the named field, got %+v", c.Server)
+	}
+	if c.Burst != 10 {
+		t.Errorf("it should reset promoted fields, got %d", c.Burst)
+	}
+	if c.Debug {
+		t.Errorf("it should zero fields without a default")
+	}
+	if c.Backup == nil || c.Backup.Host != "localhost" || c.Backup.Timeout != 0 {
+		t.Errorf("it should allocate nil pointers on the way, got %+v", c.Backup)
+	}
+	if c.Name != "set" {
+		t.Errorf("it should keep other fields, got %s", c.Name)
+	}
+
+	if err := ResetField(c, "Server"); err != nil || c.Server.Host != "localhost" {
+		t.Errorf("it should reset a nested struct, got %+v, %v", c.Server, err)
+	}
+
+	for _, path := range []string{"", "Missing", "Name.Value", "resetLimits", "Server.Labels.a"} {
+		if err := ResetField(c, path); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("it should return ErrUnknownField for %q, got %v", path, err)
+		}
+	}
+
+	err := ResetField(&struct {
+		Port int `default:"80a"`
+	}{}, "Port", WithStrict(true))
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Port" {
+		t.Errorf("it should report the field path, got %v", err)
+	}
+}
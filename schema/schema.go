@@ -0,0 +1,271 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package schema for Default Loader, it generates JSON Schema documents whose defaults come from the default tags
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/godcong/dl"
+)
+
+// Draft is the JSON Schema dialect of the generated documents.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Schema is a JSON Schema document, or a subschema of one.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	// Default is the json form of the default value
+	Default json.RawMessage    `json:"default,omitempty"`
+	Defs    map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Generate returns the JSON Schema of T, which should be a struct.
+// opts configure how the default tags are read, like dl.WithTagName.
+func Generate[T any](opts ...dl.Option) (*Schema, error) {
+	return GenerateType(reflect.TypeOf((*T)(nil)).Elem(), opts...)
+}
+
+// GenerateType returns the JSON Schema of the struct type t, t may be a pointer to a struct.
+// Properties are named by their json tags, and get the defaults of their tags in the `default` keyword.
+// Structs which hold themselves are described once in `$defs` and referenced.
+// opts configure how the default tags are read, like dl.WithTagName.
+func GenerateType(t reflect.Type, opts ...dl.Option) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: %s is not a struct", t)
+	}
+
+	g := &generator{
+		root:      t,
+		defaults:  make(map[string]any),
+		recursive: make(map[reflect.Type]bool),
+	}
+	for _, f := range dl.DescribeType(t, opts...) {
+		if f.Value != nil {
+			g.defaults[f.Path] = f.Value
+		}
+	}
+
+	s, err := g.structSchema("", t)
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = Draft
+	s.Title = t.Name()
+	s.Defs = g.defs
+	return s, nil
+}
+
+// generator builds the schema of a struct type.
+type generator struct {
+	root reflect.Type
+	// defaults are the parsed default values by the path of dl.FieldDefault
+	defaults map[string]any
+	// stack holds the struct types being generated, recursive are the ones found on it again
+	stack     []reflect.Type
+	recursive map[reflect.Type]bool
+	defs      map[string]*Schema
+}
+
+func (g *generator) structSchema(path string, t reflect.Type) (*Schema, error) {
+	for _, typ := range g.stack {
+		if typ == t {
+			g.recursive[t] = true
+			return &Schema{Ref: g.ref(t)}, nil
+		}
+	}
+	g.stack = append(g.stack, t)
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	err := g.addProperties(s, path, t)
+	g.stack = g.stack[:len(g.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	if g.recursive[t] && t != g.root {
+		if g.defs == nil {
+			g.defs = make(map[string]*Schema)
+		}
+		g.defs[t.Name()] = s
+		return &Schema{Ref: g.ref(t)}, nil
+	}
+	return s, nil
+}
+
+func (g *generator) ref(t reflect.Type) string {
+	if t == g.root {
+		return "#"
+	}
+	return "#/$defs/" + t.Name()
+}
+
+// addProperties adds the fields of the struct t to s like encoding/json encodes them,
+// fields of embedded structs without a json name are promoted.
+func (g *generator) addProperties(s *Schema, path string, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			p := path
+			if sf.IsExported() {
+				p = join(path, sf.Name)
+			}
+			if err := g.addProperties(s, p, ft); err != nil {
+				return err
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		p := join(path, sf.Name)
+		prop, err := g.typeSchema(p, sf.Type)
+		if err != nil {
+			return err
+		}
+		quoted := hasOption(opts, "string") && isScalar(ft)
+		if quoted {
+			prop = &Schema{Type: "string"}
+		}
+		if v, ok := g.defaults[p]; ok {
+			if prop.Default, err = marshalDefault(v, quoted); err != nil {
+				return fmt.Errorf("schema: default of %s: %w", p, err)
+			}
+		}
+		s.Properties[name] = prop
+	}
+	return nil
+}
+
+func (g *generator) typeSchema(path string, t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case implements(t, jsonMarshalerType):
+		// the json form is unknown
+		return &Schema{}, nil
+	case implements(t, textMarshalerType) || implements(t, textUnmarshalerType):
+		return &Schema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && !implements(t.Elem(), textMarshalerType) {
+			return &Schema{Type: "string", ContentEncoding: "base64"}, nil
+		}
+		items, err := g.typeSchema(path+"[]", t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s := &Schema{Type: "array", Items: items}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			s.MaxItems = &n
+		}
+		return s, nil
+	case reflect.Map:
+		values, err := g.typeSchema(path+"[]", t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.structSchema(path, t)
+	default:
+		// interfaces accept any value, channels and functions are not encoded
+		return &Schema{}, nil
+	}
+}
+
+// implements reports whether t or a pointer to t implements iface.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// isScalar reports whether the string option of encoding/json applies to the kind of t.
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// marshalDefault encodes v like encoding/json, a quoted value is encoded in a json string.
+func marshalDefault(v any, quoted bool) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil || !quoted {
+		return data, err
+	}
+	return json.Marshal(string(data))
+}
+
+// hasOption reports whether the comma separated options of a json tag hold option.
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/godcong/dl"
+)
+
+type TLS struct {
+	Enabled bool   `json:"enabled" default:"true"`
+	Cert    string `json:"cert,omitempty"`
+}
+
+type Server struct {
+	Host    string        `json:"host" default:"localhost"`
+	Port    int           `json:"port" default:"80"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	TLS     *TLS          `json:"tls"`
+}
+
+type Node struct {
+	Name     string  `json:"name" default:"node"`
+	Children []*Node `json:"children"`
+}
+
+type base struct {
+	Version string `json:"version" default:"v1"`
+}
+
+type Config struct {
+	base
+	Name    string            `json:"name" cfg:"custom" default:"app"`
+	Debug   bool              `json:"debug,omitempty,string"`
+	Retries int               `json:"retries,string" default:"3"`
+	Label   string            `json:"label,string" default:"dev"`
+	Servers []Server          `json:"servers" default:"[{\"host\": \"a\"}]"`
+	Labels  map[string]string `json:"labels" default:"{\"env\": \"dev\"}"`
+	Ports   [2]int            `json:"ports"`
+	Start   time.Time         `json:"start"`
+	Data    []byte            `json:"data"`
+	Any     any               `json:"any"`
+	Tree    Node              `json:"tree"`
+	Skip    string            `json:"-" default:"skip"`
+	secret  string            //nolint:unused
+}
+
+func TestGenerate(t *testing.T) {
+	s, err := Generate[Config]()
+	if err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if s.Schema != Draft || s.Title != "Config" || s.Type != "object" {
+		t.Errorf("it should describe the root object, got %+v", s)
+	}
+
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	if len(s.Properties) != 12 || s.Properties["Skip"] != nil || s.Properties["secret"] != nil {
+		t.Errorf("it should name properties like encoding/json, got %v", names)
+	}
+
+	defaults := map[string]string{
+		"version": `"v1"`,
+		"name":    `"app"`,
+		"servers": `[{"host":"a","port":80,"timeout":5000000000,"tls":null}]`,
+		"labels":  `{"env":"dev"}`,
+		"retries": `"3"`,
+		"label":   `"\"dev\""`,
+	}
+	for name, p := range s.Properties {
+		if string(p.Default) != defaults[name] {
+			t.Errorf("it should set the default of %s to %s, got %s", name, defaults[name], p.Default)
+		}
+	}
+
+	server := s.Properties["servers"].Items
+	if server.Type != "object" || string(server.Properties["port"].Default) != "80" ||
+		string(server.Properties["timeout"].Default) != "5000000000" {
+		t.Errorf("it should describe slice elements with their defaults, got %+v", server)
+	}
+	if tls := server.Properties["tls"]; tls.Type != "object" || string(tls.Properties["enabled"].Default) != "true" {
+		t.Errorf("it should describe pointers by their element, got %+v", tls)
+	}
+
+	types := map[string]string{"debug": "string", "retries": "string", "ports": "array", "start": "string", "data": "string", "any": ""}
+	for name, typ := range types {
+		if s.Properties[name].Type != typ {
+			t.Errorf("it should set the type of %s to %q, got %q", name, typ, s.Properties[name].Type)
+		}
+	}
+	if s.Properties["labels"].AdditionalProperties.Type != "string" {
+		t.Errorf("it should describe map values")
+	}
+	if *s.Properties["ports"].MaxItems != 2 || s.Properties["start"].Format != "date-time" {
+		t.Errorf("it should describe arrays and times")
+	}
+
+	tree := s.Properties["tree"]
+	if tree.Ref != "#/$defs/Node" || s.Defs["Node"] == nil ||
+		s.Defs["Node"].Properties["children"].Items.Ref != "#/$defs/Node" {
+		t.Errorf("it should reference recursive structs, got %+v", tree)
+	}
+	if string(s.Defs["Node"].Properties["name"].Default) != `"node"` {
+		t.Errorf("it should keep the defaults of recursive structs")
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("it should marshal the schema: %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil || doc["$schema"] != Draft {
+		t.Errorf("it should marshal the keywords, got %s", data)
+	}
+}
+
+func TestGenerateType(t *testing.T) {
+	s, err := GenerateType(reflect.TypeOf(&Config{}), dl.WithTagName("cfg"))
+	if err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if string(s.Properties["name"].Default) != `"custom"` || s.Properties["version"].Default != nil {
+		t.Errorf("it should read the tag name of the options, got %s", s.Properties["name"].Default)
+	}
+
+	if _, err := GenerateType(reflect.TypeOf(0)); err == nil {
+		t.Errorf("it should return an error for non-struct types")
+	}
+}
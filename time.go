@@ -0,0 +1,90 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const layoutTagName = "layout"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// timeLayouts are tried in order for time.Time defaults without a layout tag.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// setTimeField sets time.Time fields by parseTime, and *time.Location fields from a location name,
+// like `UTC`, `Local` or `Asia/Shanghai`.
+func (w *walker) setTimeField(field reflect.Value, def *fieldDefault) (bool, error) {
+	if def.raw == "" {
+		return false, nil
+	}
+	var val any
+	var err error
+	switch field.Type() {
+	case timeType:
+		val, err = parseTime(def.raw, def.layout, w.l.now)
+	case locationType:
+		val, err = time.LoadLocation(def.raw)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		if w.l.strict {
+			return true, w.fail(field, def.raw, parseError(field.Kind(), def.raw, err))
+		}
+		return true, nil
+	}
+	field.Set(reflect.ValueOf(val))
+	return true, nil
+}
+
+// parseTime parses s by layout, or else by the first of timeLayouts which matches.
+// The keywords `now` and `today`, the start of the day of now, may be followed by a duration, like `now+7d`.
+func parseTime(s, layout string, now func() time.Time) (time.Time, error) {
+	for _, keyword := range []string{"now", "today"} {
+		rest := strings.TrimPrefix(s, keyword)
+		if rest == s || (rest != "" && rest[0] != '+' && rest[0] != '-') {
+			continue
+		}
+		t := now()
+		if keyword == "today" {
+			y, m, d := t.Date()
+			t = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		}
+		if rest == "" {
+			return t, nil
+		}
+		d, err := parseDuration(rest)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.Add(d), nil
+	}
+
+	if layout != "" {
+		return time.Parse(layout, s)
+	}
+	var err error
+	for _, layout := range timeLayouts {
+		t, e := time.Parse(layout, s)
+		if e == nil {
+			return t, nil
+		}
+		if err == nil {
+			err = e
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,88 @@
+package dl
+
+import (
+	"errors"
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	type schedule struct {
+		RFC3339  time.Time      `default:"2024-01-02T03:04:05Z"`
+		Date     time.Time      `default:"2024-01-02"`
+		Layout   time.Time      `default:"02/01/2024" layout:"02/01/2006"`
+		Now      time.Time      `default:"now"`
+		Later    time.Time      `default:"now+24h"`
+		Earlier  *time.Time     `default:"now-1h30m"`
+		Today    time.Time      `default:"today"`
+		Tomorrow time.Time      `default:"today+24h"`
+		UTC      *time.Location `default:"UTC"`
+		Zone     *time.Location `default:"Asia/Shanghai"`
+		Local    *time.Location `default:"Local"`
+		Unset    *time.Location
+	}
+
+	s := &schedule{}
+	if err := Load(s, clock, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	tests := map[string][2]time.Time{
+		"RFC3339":  {s.RFC3339, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"Date":     {s.Date, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		"Layout":   {s.Layout, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		"Now":      {s.Now, now},
+		"Later":    {s.Later, now.Add(24 * time.Hour)},
+		"Earlier":  {*s.Earlier, now.Add(-90 * time.Minute)},
+		"Today":    {s.Today, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		"Tomorrow": {s.Tomorrow, time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for name, tt := range tests {
+		if !tt[0].Equal(tt[1]) {
+			t.Errorf("it should set %s to %s, got %s", name, tt[1], tt[0])
+		}
+	}
+	if s.UTC != time.UTC || s.Zone == nil || s.Zone.String() != "Asia/Shanghai" || s.Local != time.Local {
+		t.Errorf("it should load locations by name, got %v %v %v", s.UTC, s.Zone, s.Local)
+	}
+	if s.Unset != nil {
+		t.Errorf("it should not set locations without a default, got %v", s.Unset)
+	}
+
+	for _, tag := range []string{"tomorrow", "now+1x", "2024-13-01"} {
+		if _, err := parseTime(tag, "", time.Now); err == nil {
+			t.Errorf("it should not parse %q", tag)
+		}
+	}
+	err := NewLoader(WithStrict(true)).Load(&struct {
+		Zone *time.Location `default:"Mars/Olympus"`
+	}{})
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Errorf("it should return a *ParseError for unknown locations, got %v", err)
+	}
+
+	t.Run("flags", func(t *testing.T) {
+		type config struct {
+			Day  time.Time      `default:"2024-01-02" layout:"2006-01-02"`
+			Zone *time.Location `default:"UTC"`
+		}
+		c := &config{}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		if err := BindFlags(fs, c, ""); err != nil {
+			t.Fatal(err)
+		}
+		if fs.Lookup("zone").DefValue != "UTC" {
+			t.Errorf("it should format locations by name, got %s", fs.Lookup("zone").DefValue)
+		}
+		if err := fs.Parse([]string{"-day=2024-05-06", "-zone=Asia/Shanghai"}); err != nil {
+			t.Fatalf("it should parse the flags: %v", err)
+		}
+		if c.Day.Day() != 6 || c.Zone.String() != "Asia/Shanghai" {
+			t.Errorf("it should parse flags by the layout and location name, got %+v", c)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2024 GodCong. All rights reserved.
+
+// Package dl for Default Loader
+package dl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const unitTagName = "unit"
+
+const (
+	// UnitBytes parses sizes with SI and IEC suffixes, like `64MiB` or `1.5GB`.
+	UnitBytes = "bytes"
+	// UnitDuration parses durations like time.ParseDuration, with the additional units `d` and `w`, like `7d` or `1w12h`.
+	UnitDuration = "duration"
+)
+
+// ErrUnknownUnit is returned when a unit tag names no known unit.
+var ErrUnknownUnit = errors.New("unknown unit")
+
+var errOverflow = errors.New("value out of range")
+
+// units holds the unit registered by RegisterUnit for each type.
+var units sync.Map
+
+// byteSizes are the multipliers of the lower cased byte suffixes.
+var byteSizes = map[string]uint64{
+	"": 1, "b": 1,
+	"k": 1e3, "kb": 1e3, "ki": 1 << 10, "kib": 1 << 10,
+	"m": 1e6, "mb": 1e6, "mi": 1 << 20, "mib": 1 << 20,
+	"g": 1e9, "gb": 1e9, "gi": 1 << 30, "gib": 1 << 30,
+	"t": 1e12, "tb": 1e12, "ti": 1 << 40, "tib": 1 << 40,
+	"p": 1e15, "pb": 1e15, "pi": 1 << 50, "pib": 1 << 50,
+	"e": 1e18, "eb": 1e18, "ei": 1 << 60, "eib": 1 << 60,
+}
+
+// RegisterUnit parses the integer fields of type T by unit for every Loader, like a `unit` tag on each of them.
+// A unit set by WithUnit takes precedence over the registered one.
+func RegisterUnit[T any](unit string) {
+	units.Store(reflect.TypeOf((*T)(nil)).Elem(), unit)
+}
+
+// SetUnit parses s by unit into the integer referenced by ptr, checking it fits its size.
+// The unit registered for T is used when unit is empty, or else s is parsed by the kind of T like a default tag,
+// other kinds than booleans and numbers return an error.
+// It is used by the generated Default methods for unit tags and for values like `64MiB` of named types.
+func SetUnit[T any](ptr *T, s, unit string) error {
+	field := reflect.ValueOf(ptr).Elem()
+	if unit == "" {
+		unit = defaultLoader.unitFor(field.Type())
+	}
+	if unit == "" {
+		if !isScalar(field.Kind()) {
+			return parseError(field.Kind(), s, errors.New("no unit to parse the value"))
+		}
+		return parseScalar(field, s, "")
+	}
+	return setUnitField(field, s, unit)
+}
+
+// unitFor returns the unit of the Loader or the registered one for t.
+func (l *Loader) unitFor(t reflect.Type) string {
+	if u, ok := l.units[t]; ok {
+		return u
+	}
+	if u, ok := units.Load(t); ok {
+		return u.(string)
+	}
+	return ""
+}
+
+// setUnitField sets an integer field from s parsed by unit.
+func setUnitField(field reflect.Value, s, unit string) error {
+	var n uint64
+	var neg bool
+	var err error
+	switch unit {
+	case UnitBytes:
+		n, err = parseBytes(s)
+	case UnitDuration:
+		var d time.Duration
+		d, err = parseDuration(s)
+		if neg = d < 0; neg {
+			n = uint64(-d)
+		} else {
+			n = uint64(d)
+		}
+	default:
+		err = fmt.Errorf("%w %q", ErrUnknownUnit, unit)
+	}
+	if err != nil {
+		return parseError(field.Kind(), s, err)
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n > math.MaxInt64 {
+			return parseError(field.Kind(), s, errOverflow)
+		}
+		val := int64(n)
+		if neg {
+			val = -val
+		}
+		if field.OverflowInt(val) {
+			return parseError(field.Kind(), s, errOverflow)
+		}
+		field.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if neg || field.OverflowUint(n) {
+			return parseError(field.Kind(), s, errOverflow)
+		}
+		field.SetUint(n)
+	default:
+		return parseError(field.Kind(), s, fmt.Errorf("unit %s needs an integer", unit))
+	}
+	return nil
+}
+
+// parseBytes parses a size like `64MiB`, `1.5 GB` or `512`, suffixes are case-insensitive.
+func parseBytes(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	num, suffix := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	size, ok := byteSizes[suffix]
+	if !ok || num == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	if !strings.Contains(num, ".") {
+		n, err := strconv.ParseUint(num, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		if n > math.MaxUint64/size {
+			return 0, errOverflow
+		}
+		return n * size, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	f *= float64(size)
+	if f >= math.MaxUint64 {
+		return 0, errOverflow
+	}
+	return uint64(f), nil
+}
+
+// parseDuration parses a duration like time.ParseDuration, with the additional units `d` for 24h and `w` for 7d.
+func parseDuration(s string) (time.Duration, error) {
+	orig := s
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	if s == "0" {
+		return 0, nil
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+
+	var total time.Duration
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		j := strings.IndexFunc(s[i:], func(r rune) bool { return (r >= '0' && r <= '9') || r == '.' })
+		if j < 0 {
+			j = len(s) - i
+		}
+		num, unit := s[:i], s[i:i+j]
+		s = s[i+j:]
+
+		var d time.Duration
+		switch unit {
+		case "d", "w":
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", orig)
+			}
+			f *= float64(24 * time.Hour)
+			if unit == "w" {
+				f *= 7
+			}
+			if f >= math.MaxInt64 {
+				return 0, errOverflow
+			}
+			d = time.Duration(f)
+		default:
+			var err error
+			if d, err = time.ParseDuration(num + unit); err != nil {
+				return 0, err
+			}
+		}
+		if total > math.MaxInt64-d {
+			return 0, errOverflow
+		}
+		total += d
+	}
+	if neg {
+		return -total, nil
+	}
+	return total, nil
+}
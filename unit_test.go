@@ -0,0 +1,173 @@
+package dl
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type ByteSize int64
+
+func TestParseBytes(t *testing.T) {
+	tests := map[string]uint64{
+		"512":     512,
+		"64MiB":   64 << 20,
+		"64mib":   64 << 20,
+		"1KB":     1000,
+		"1k":      1000,
+		"1.5GiB":  3 << 29,
+		"2 TB":    2e12,
+		"16EiB":   0,
+		"1.5x":    0,
+		"MiB":     0,
+		"1..5MiB": 0,
+	}
+	for in, out := range tests {
+		n, err := parseBytes(in)
+		if out == 0 {
+			if err == nil {
+				t.Errorf("parseBytes(%q) should return an error, got %d", in, n)
+			}
+			continue
+		}
+		if err != nil || n != out {
+			t.Errorf("parseBytes(%q) returns %d, %v, expected %d", in, n, err, out)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"0":       0,
+		"1h30m":   90 * time.Minute,
+		"7d":      7 * 24 * time.Hour,
+		"1w12h":   7*24*time.Hour + 12*time.Hour,
+		"1.5d":    36 * time.Hour,
+		"-2d":     -48 * time.Hour,
+		"+1d":     24 * time.Hour,
+		"500ms":   500 * time.Millisecond,
+		"100000w": -1,
+		"d":       -1,
+		"7":       -1,
+		"":        -1,
+	}
+	for in, out := range tests {
+		d, err := parseDuration(in)
+		if out == -1 {
+			if err == nil {
+				t.Errorf("parseDuration(%q) should return an error, got %s", in, d)
+			}
+			continue
+		}
+		if err != nil || d != out {
+			t.Errorf("parseDuration(%q) returns %s, %v, expected %s", in, d, err, out)
+		}
+	}
+}
+
+// registerUnit registers unit like RegisterUnit until the test ends.
+func registerUnit[T any](t *testing.T, unit string) {
+	RegisterUnit[T](unit)
+	t.Cleanup(func() { units.Delete(reflect.TypeOf((*T)(nil)).Elem()) })
+}
+
+func TestUnit(t *testing.T) {
+	registerUnit[ByteSize](t, UnitBytes)
+
+	type config struct {
+		Buffer    int           `default:"64MiB" unit:"bytes"`
+		Small     uint8         `default:"255B" unit:"bytes"`
+		Retention time.Duration `default:"7d" unit:"duration"`
+		Seconds   *int64        `default:"1w" unit:"duration"`
+		Size      ByteSize      `default:"1GB"`
+		Plain     int           `default:"10"`
+	}
+	c := &config{}
+	if err := Load(c, WithStrict(true)); err != nil {
+		t.Fatalf("it should not return an error: %v", err)
+	}
+	if c.Buffer != 64<<20 || c.Small != 255 || c.Retention != 7*24*time.Hour || *c.Seconds != int64(7*24*time.Hour) {
+		t.Errorf("it should parse values by the unit tag, got %+v", c)
+	}
+	if c.Size != 1e9 || c.Plain != 10 {
+		t.Errorf("it should parse values by the registered unit, got %+v", c)
+	}
+
+	var pe *ParseError
+	err := Load(&struct {
+		Small uint8 `default:"1KiB" unit:"bytes"`
+	}{}, WithStrict(true))
+	if !errors.As(err, &pe) || !errors.Is(err, errOverflow) {
+		t.Errorf("it should check the bit size of the field, got %v", err)
+	}
+	err = Load(&struct {
+		Size uint `default:"-1d" unit:"duration"`
+	}{}, WithStrict(true))
+	if !errors.Is(err, errOverflow) {
+		t.Errorf("it should not set negative values to unsigned fields, got %v", err)
+	}
+	err = Load(&struct {
+		Size int `default:"1" unit:"parsecs"`
+	}{}, WithStrict(true))
+	if !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("it should return ErrUnknownUnit, got %v", err)
+	}
+
+	type limits struct {
+		Size int32 `default:"1KiB"`
+	}
+	l := &limits{}
+	if err := NewLoader(WithUnit[int32](UnitBytes)).Load(l); err != nil || l.Size != 1024 {
+		t.Errorf("it should use the unit of the loader, got %d, %v", l.Size, err)
+	}
+
+	type memory int64
+	type host struct {
+		Memory memory `default:"1KiB"`
+	}
+	_ = Load(&host{})
+	registerUnit[memory](t, UnitBytes)
+	h := &host{}
+	if err := Load(h, WithStrict(true)); err != nil || h.Memory != 1024 {
+		t.Errorf("it should use a unit registered after the plan is compiled, got %d, %v", h.Memory, err)
+	}
+
+	var size ByteSize
+	if err := SetUnit(&size, "2KiB", ""); err != nil || size != 2048 {
+		t.Errorf("it should set values by the registered unit, got %d, %v", size, err)
+	}
+	var timeout time.Duration
+	if err := SetUnit(&timeout, "5s", ""); err != nil || timeout != 5*time.Second {
+		t.Errorf("it should parse by the kind without a unit, got %s, %v", timeout, err)
+	}
+	type version string
+	v := version("1.0")
+	if err := SetUnit(&v, "1.2.3", ""); err == nil || v != "1.0" {
+		t.Errorf("it should return an error for kinds it cannot set, got %s, %v", v, err)
+	}
+	var small int8
+	if err := SetUnit(&small, "1KiB", UnitBytes); !errors.Is(err, errOverflow) {
+		t.Errorf("it should check the size of the value, got %v", err)
+	}
+
+	t.Run("flags", func(t *testing.T) {
+		c := &config{}
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		if err := BindFlags(fs, c, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := fs.Parse([]string{"-buffer=1MiB", "-retention=2w"}); err != nil {
+			t.Fatalf("it should parse the flags: %v", err)
+		}
+		if c.Buffer != 1<<20 || c.Retention != 14*24*time.Hour {
+			t.Errorf("it should parse flags by the unit tag, got %+v", c)
+		}
+	})
+
+	now := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	if tm, err := parseTime("now+7d", "", func() time.Time { return now }); err != nil || !tm.Equal(now.AddDate(0, 0, 7)) {
+		t.Errorf("it should accept day units in time keywords, got %s, %v", tm, err)
+	}
+}